@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+const (
+	// circleSpacing is the distance between neighbour charges arranged on a circle, in sprite widths
+	circleSpacing = 1.5
+	// lineSpacing is the largest distance between neighbour charges created by CreateLine, in sprite widths
+	lineSpacing    = 2
+	maxLineCharges = 100
+)
+
+// ArrangeCircle places all the charges evenly spaced on a circle centered in the playfield, as an undoable action.
+// The radius grows with the number of charges so they don't overlap, without leaving the playfield.
+func (g *Game) ArrangeCircle() {
+	n := len(g.sprites)
+	if n == 0 {
+		return
+	}
+	g.pushUndo()
+	width, height := g.playfield()
+	w, h := neutralImage.Size()
+	radius := float64(n) * float64(w) * circleSpacing / (2 * math.Pi)
+	radius = math.Max(radius, float64(w))
+	radius = math.Min(radius, math.Min(float64(width-w), float64(height-h))/2)
+	cx, cy := float64(width)/2, float64(height)/2
+	for i, s := range g.sprites {
+		// starting at the top, so a single pair is arranged vertically
+		a := 2*math.Pi*float64(i)/float64(n) - math.Pi/2
+		sw, sh := s.image.Size()
+		s.x = int(math.Round(cx + radius*math.Cos(a) - float64(sw)/2))
+		s.y = int(math.Round(cy + radius*math.Sin(a) - float64(sh)/2))
+		s.MoveBy(0, 0)
+		s.vx, s.vy = 0, 0
+		s.clearTrail()
+	}
+}
+
+// CreateLine adds n charges of q C each on a horizontal line centered in the playfield, equally spaced,
+// as an undoable action. The new charges are left selected, and can be moved one by one afterwards.
+func (g *Game) CreateLine(n int, q float64) {
+	if n <= 0 {
+		return
+	}
+	g.pushUndo()
+	width, height := g.playfield()
+	w, h := neutralImage.Size()
+	spacing := float64(w) * lineSpacing
+	if n > 1 {
+		spacing = math.Min(spacing, float64(width-w)/float64(n-1))
+	}
+	left := float64(width)/2 - spacing*float64(n-1)/2
+	g.selectOnly(nil)
+	for i := 0; i < n; i++ {
+		x := int(math.Round(left + spacing*float64(i) - float64(w)/2))
+		s := g.addSprite("Q"+strconv.Itoa(len(g.sprites)), x, height/2-h/2, q)
+		s.image = imageFor(q)
+		s.MoveBy(0, 0)
+		g.selected[s] = struct{}{}
+		g.ChosenSprite = s
+	}
+}
+
+// CreateCapacitor adds two horizontal plates of n charges each centered in the playfield, as an undoable action:
+// a plate of q C charges on top and a plate of -q C charges gap px below it, the charges of a plate spacing px apart.
+// The spacing and gap are reduced when needed to fit the plates in the playfield.
+// The new charges are left selected, so the capacitor can be moved as a whole.
+func (g *Game) CreateCapacitor(n int, gap, spacing int, q float64) {
+	if n <= 0 {
+		return
+	}
+	g.pushUndo()
+	width, height := g.playfield()
+	w, h := neutralImage.Size()
+	if n > 1 {
+		spacing = int(math.Min(float64(spacing), float64(width-w)/float64(n-1)))
+	}
+	gap = int(math.Min(float64(gap), float64(height-h)))
+	left := width/2 - spacing*(n-1)/2 - w/2
+	top := height/2 - gap/2 - h/2
+	g.selectOnly(nil)
+	for _, plate := range []struct {
+		y int
+		q float64
+	}{{top, q}, {top + gap, -q}} {
+		for i := 0; i < n; i++ {
+			s := g.addSprite("Q"+strconv.Itoa(len(g.sprites)), left+spacing*i, plate.y, plate.q)
+			s.image = imageFor(plate.q)
+			s.MoveBy(0, 0)
+			g.selected[s] = struct{}{}
+			g.ChosenSprite = s
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// recoveryFileName is the scene file written by the auto-save, offered to be loaded on the next launch
+const recoveryFileName = "scene-recovery.json"
+
+// updateAutosave saves the scene to the recovery file every g.autosave while auto-saving is on.
+// Nothing is saved while the recovery file of the previous session is offered, so it isn't overwritten
+// before the user chooses to load it.
+func (g *Game) updateAutosave() {
+	if g.autosave <= 0 || g.recoveryOffered {
+		return
+	}
+	g.autosaveTicks++
+	if float64(g.autosaveTicks) < g.autosave.Seconds()*float64(ebiten.MaxTPS()) {
+		return
+	}
+	g.autosaveTicks = 0
+	if err := g.Save(recoveryFileName); err != nil {
+		log.Printf("could not auto-save the scene: %v", err)
+	}
+}
+
+// offerRecovery offers to load the recovery file if one was left by a previous session
+func (g *Game) offerRecovery() {
+	if _, err := g.storage.ReadFile(recoveryFileName); err == nil {
+		g.recoveryOffered = true
+	}
+}
+
+// answerRecovery loads the recovery file offered, or discards the offer if load is false
+func (g *Game) answerRecovery(load bool) {
+	g.recoveryOffered = false
+	if !load {
+		return
+	}
+	if err := g.Load(recoveryFileName); err != nil {
+		log.Printf("could not load the recovery file: %v", err)
+	}
+}
+
+// recoveryReadout offers the recovery file until it is loaded or discarded
+func recoveryReadout(g *Game) coloredText {
+	if !g.recoveryOffered {
+		return coloredText{}
+	}
+	return coloredText{"Auto-saved scene found ('F12' load, Shift+'F12' discard)", nullPointColor}
+}
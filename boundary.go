@@ -0,0 +1,44 @@
+package main
+
+// BoundaryMode is what happens to the moving charges reaching the edges of the playfield
+type BoundaryMode int
+
+const (
+	// BoundaryClamp stops the charges at the edges, like when dragging them
+	BoundaryClamp BoundaryMode = iota
+	// BoundaryWrap makes the charges leaving by one edge come back by the opposite one, as on a torus
+	BoundaryWrap
+	// BoundaryBounce reverses the velocity of the charges hitting the edges
+	BoundaryBounce
+	// BoundaryAbsorb removes the charges hitting the edges
+	BoundaryAbsorb
+	boundaryModes
+)
+
+var boundaryNames = [...]string{
+	BoundaryClamp:  "clamp",
+	BoundaryWrap:   "wrap",
+	BoundaryBounce: "bounce",
+	BoundaryAbsorb: "absorb",
+}
+
+func (m BoundaryMode) String() string {
+	return boundaryNames[m]
+}
+
+// next returns the mode following m, going back to the first one after the last
+func (m BoundaryMode) next() BoundaryMode {
+	return (m + 1) % boundaryModes
+}
+
+// wrap brings v back into [0, size) as if both ends were joined
+func wrap(v, size int) int {
+	if size <= 0 {
+		return 0
+	}
+	v %= size
+	if v < 0 {
+		v += size
+	}
+	return v
+}
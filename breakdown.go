@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+// breakdownScrollTicks is how many ticks each line stays at the top of the breakdown panel when it scrolls
+const breakdownScrollTicks = 60
+
+// contribution is the force exerted by one charge on the chosen one
+type contribution struct {
+	s         *Sprite
+	magnitude float64 // in N
+	a         float64 // direction of the force on the chosen charge, in rads
+	repulsion bool
+}
+
+// contributions returns the force each other charge exerts on s, the strongest first.
+// Their sum is the net force on s, as forces add up.
+func (g *Game) contributions(s *Sprite) []contribution {
+	list := []contribution{}
+	for _, other := range g.sprites {
+		if other == s {
+			continue
+		}
+		f := force(s, other)
+		// a repulsion pushes s away from other, an attraction pulls it towards other
+		a := angle(s, other)
+		if f < 0 {
+			a += math.Pi
+		}
+		list = append(list, contribution{other, math.Abs(f), a, f > 0})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].magnitude > list[j].magnitude
+	})
+	return list
+}
+
+// updateBreakdown advances the scrolling of the breakdown panel, starting over when another charge is chosen
+func (g *Game) updateBreakdown() {
+	if g.breakdownSprite != g.ChosenSprite {
+		g.breakdownSprite = g.ChosenSprite
+		g.breakdownTicks = 0
+		return
+	}
+	g.breakdownTicks++
+}
+
+// drawBreakdown lists the force of each other charge on the chosen one in a panel on the right of the playfield,
+// below the readouts. When the list doesn't fit the panel, it scrolls by one line every breakdownScrollTicks.
+func drawBreakdown(screen *ebiten.Image, g *Game) {
+	s := g.ChosenSprite
+	if s == nil {
+		return
+	}
+	list := g.contributions(s)
+	if len(list) == 0 {
+		return
+	}
+	lines := []coloredText{}
+	for _, c := range list {
+		str, clr := formatValue("%.2e N", c.magnitude)
+		if clr != offScaleColor {
+			clr = interactionColor(s.charge, c.s.charge)
+		}
+		lines = append(lines, coloredText{fmt.Sprintf("%s: %s at %.0f°", c.s.name, str, c.a*180/math.Pi), clr})
+	}
+
+	lineHeight := fontHeight + fontHeight/2
+	margin := fontHeight / 2
+	title := "Forces on " + s.name
+	width := font.MeasureString(g.Font, title).Ceil()
+	for _, l := range lines {
+		if w := font.MeasureString(g.Font, l.text).Ceil(); w > width {
+			width = w
+		}
+	}
+	width += 2 * margin
+	_, playfieldHeight := g.playfield()
+	top := panelTop(g)
+	// the title and as many lines as fit above the texts drawn at the bottom of the playfield
+	visible := (playfieldHeight-3*fontHeight-top-margin)/lineHeight - 1
+	if visible < 1 {
+		return
+	}
+	first := 0
+	if overflow := len(lines) - visible; overflow > 0 {
+		first = (g.breakdownTicks / breakdownScrollTicks) % (overflow + 1)
+	} else {
+		visible = len(lines)
+	}
+	x := g.width - width - fullScreenWidth*.01
+	height := (visible+1)*lineHeight + margin
+	drawRectangle(screen, float64(x), float64(top), float64(width), float64(height), color.NRGBA{0x00, 0x00, 0x00, 0xc0})
+	text.Draw(screen, title, g.Font, x+margin, top+lineHeight, color.White)
+	for i, l := range lines[first : first+visible] {
+		text.Draw(screen, l.text, g.Font, x+margin, top+(i+2)*lineHeight, overlayColor(l.color))
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+// button is an on-screen button, so the game can be used on a touchscreen without a keyboard.
+// Buttons are stacked on the left edge of the playfield, index being their position from the top.
+type button struct {
+	label  string
+	index  int
+	action func(g *Game)
+}
+
+// newButtons creates the on-screen buttons of the game
+func newButtons() []*button {
+	return []*button{
+		{label: "Add", index: 0, action: (*Game).addRandomCharge},
+		{label: "Delete", index: 1, action: (*Game).deleteSelected},
+	}
+}
+
+// bounds returns the position and size of the button on screen, centered vertically on the playfield
+func (b *button) bounds() (x, y, width, height int) {
+	width, height = buttonWidth, fontHeight*2
+	_, playfieldHeight := theGame.playfield()
+	count := len(theGame.buttons)
+	top := playfieldHeight/2 - (count*height+(count-1)*buttonSpacing)/2
+	return buttonSpacing, top + b.index*(height+buttonSpacing), width, height
+}
+
+// In returns true if (x, y) is in the button, and false otherwise.
+func (b *button) In(x, y int) bool {
+	bx, by, w, h := b.bounds()
+	return x >= bx && x < bx+w && y >= by && y < by+h
+}
+
+// buttonAt returns the button at (x, y) or nil if none is there
+func (g *Game) buttonAt(x, y int) *button {
+	for _, b := range g.buttons {
+		if b.In(x, y) {
+			return b
+		}
+	}
+	return nil
+}
+
+// pressed reports whether a stroke that started on the button is still over it
+func (g *Game) pressed(b *button) bool {
+	for _, s := range g.strokes {
+		if s.DraggingObject() == b && b.In(s.Position()) {
+			return true
+		}
+	}
+	return false
+}
+
+// drawButtons draws the on-screen buttons, shifted down and darker while pressed
+func drawButtons(screen *ebiten.Image, g *Game) {
+	for _, b := range g.buttons {
+		x, y, w, h := b.bounds()
+		clr := color.NRGBA{0x60, 0x60, 0x60, 0xe0}
+		if g.pressed(b) {
+			y += buttonPressDepth
+			clr = color.NRGBA{0x30, 0x30, 0x30, 0xe0}
+		}
+		drawRectangle(screen, float64(x), float64(y), float64(w), float64(h), clr)
+		labelWidth := font.MeasureString(g.Font, b.label).Ceil()
+		text.Draw(screen, b.label, g.Font, x+(w-labelWidth)/2, y+h/2+fontHeight/2, color.White)
+	}
+}
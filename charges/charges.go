@@ -0,0 +1,92 @@
+// Package charges implements the physics of point charges, independently of how they are drawn,
+// so it can be imported by other simulations.
+// Positions and distances are in m, charges in C, forces in N, fields in N/C and potentials in V.
+//
+// The functions taking an exponent generalize Coulomb's law to a force falling with the distance raised to it,
+// 2 being Coulomb's law itself.
+package charges
+
+import "math"
+
+const (
+	// K is Coulomb's constant, in Nm²/C²
+	K = 8.9875517923e9
+	// MinDistance is the smallest distance between two particles, in m,
+	// so coincident particles don't produce infinite forces
+	MinDistance = 0.005
+)
+
+// Particle is a point charge
+type Particle struct {
+	X, Y, Z float64 // position in m, Z is zero for particles on a plane
+	Charge  float64 // in C
+}
+
+// Distance calculates the distance (Pythagorean Theorem) between two particles, in m
+func Distance(p1, p2 Particle) float64 {
+	d := math.Sqrt((p1.X-p2.X)*(p1.X-p2.X) + (p1.Y-p2.Y)*(p1.Y-p2.Y) + (p1.Z-p2.Z)*(p1.Z-p2.Z))
+	return math.Max(d, MinDistance)
+}
+
+// Coincident reports if two particles are at the same position, where the direction between them is undefined
+func Coincident(p1, p2 Particle) bool {
+	return p1.X == p2.X && p1.Y == p2.Y && p1.Z == p2.Z
+}
+
+// Coulomb calculates the force between two charges q1 and q2 (in C) r m apart, in N, by Coulomb's law.
+// It is positive when the charges repel each other and negative when they attract.
+func Coulomb(q1, q2, r float64) float64 {
+	return K * q1 * q2 / (r * r)
+}
+
+// Force calculates the force between two particles, in N, for a force law falling with the distance
+// raised to exponent (2 for Coulomb's law).
+// It is positive when the particles repel each other and negative when they attract.
+func Force(p1, p2 Particle, exponent float64) float64 {
+	return K * p1.Charge * p2.Charge / math.Pow(Distance(p1, p2), exponent)
+}
+
+// Field calculates the electric field of a charge (in C) at a given radius (in m), in N/C,
+// for a force law falling with the distance raised to exponent
+func Field(charge, radius, exponent float64) float64 {
+	return K * charge / math.Pow(radius, exponent)
+}
+
+// Potential calculates the electric potential of a charge (in C) at a given radius (in m), in V,
+// for a force law falling with the distance raised to exponent.
+// It is K*charge/r for Coulomb's law, and -K*charge*ln(r) for exponent 1, where it has no zero at infinity.
+func Potential(charge, radius, exponent float64) float64 {
+	if exponent == 1 {
+		return -K * charge * math.Log(radius)
+	}
+	return K * charge / ((exponent - 1) * math.Pow(radius, exponent-1))
+}
+
+// Angle calculates the angle in rads of the direction going from p2 to p1 on the XY plane
+func Angle(p1, p2 Particle) float64 {
+	return math.Atan2(p1.Y-p2.Y, p1.X-p2.X)
+}
+
+// NetField sums the electric fields of the particles at p (whose charge is ignored), returning the components
+// of the net field along X and Y, in N/C. It is the force the particles would exert on a 1 C charge at p.
+// Particles at p are skipped, as the direction of their field is undefined there.
+func NetField(p Particle, particles []Particle, exponent float64) (ex, ey float64) {
+	p.Charge = 1
+	return NetForce(p, particles, exponent)
+}
+
+// NetForce sums the forces exerted on target by the other particles, returning the components
+// of the net force along X and Y, in N.
+// Particles at the same position as target are skipped, as the force direction between them is undefined.
+func NetForce(target Particle, others []Particle, exponent float64) (fx, fy float64) {
+	for _, other := range others {
+		if other.Charge == 0 || Coincident(target, other) {
+			continue
+		}
+		// a positive force is a repulsion, pushing target away from other
+		f := Force(target, other, exponent) / Distance(target, other)
+		fx += f * (target.X - other.X)
+		fy += f * (target.Y - other.Y)
+	}
+	return fx, fy
+}
@@ -0,0 +1,199 @@
+package charges
+
+import (
+	"math"
+	"testing"
+)
+
+// closeTo reports whether got is within a relative tolerance of want
+func closeTo(got, want float64) bool {
+	if want == 0 {
+		return math.Abs(got) < 1e-9
+	}
+	return math.Abs(got-want) <= 1e-9*math.Abs(want)
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name   string
+		p1, p2 Particle
+		want   float64
+	}{
+		{"same axis", Particle{X: 0}, Particle{X: 1}, 1},
+		{"3-4-5 triangle", Particle{X: 0, Y: 0}, Particle{X: 3, Y: 4}, 5},
+		{"depth", Particle{Z: 0}, Particle{Z: 2}, 2},
+		{"coincident", Particle{X: 1, Y: 1}, Particle{X: 1, Y: 1}, MinDistance},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.p1, tt.p2); !closeTo(got, tt.want) {
+			t.Errorf("%s: expected %g m, got %g m", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestForce(t *testing.T) {
+	tests := []struct {
+		name     string
+		p1, p2   Particle
+		exponent float64
+		want     float64
+	}{
+		{"unit charges 1 m apart", Particle{Charge: 1}, Particle{X: 1, Charge: 1}, 2, K},
+		{"opposite charges attract", Particle{Charge: 1}, Particle{X: 1, Charge: -1}, 2, -K},
+		{"inverse square", Particle{Charge: 1}, Particle{X: 2, Charge: 1}, 2, K / 4},
+		{"inverse cube", Particle{Charge: 1}, Particle{X: 2, Charge: 1}, 3, K / 8},
+		{"neutral", Particle{Charge: 0}, Particle{X: 1, Charge: 1}, 2, 0},
+	}
+	for _, tt := range tests {
+		if got := Force(tt.p1, tt.p2, tt.exponent); !closeTo(got, tt.want) {
+			t.Errorf("%s: expected %g N, got %g N", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCoulomb(t *testing.T) {
+	tests := []struct {
+		name      string
+		q1, q2, r float64
+		want      float64
+	}{
+		{"unit charges 1 m apart", 1, 1, 1, K},
+		{"opposite charges attract", 1, -1, 1, -K},
+		{"inverse square", 2, 1, 2, K / 2},
+	}
+	for _, tt := range tests {
+		if got := Coulomb(tt.q1, tt.q2, tt.r); !closeTo(got, tt.want) {
+			t.Errorf("%s: expected %g N, got %g N", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCoulombMatchesForce(t *testing.T) {
+	p1, p2 := Particle{Charge: 0.3}, Particle{X: 3, Y: 4, Charge: -0.2}
+	if got, want := Coulomb(p1.Charge, p2.Charge, Distance(p1, p2)), Force(p1, p2, 2); !closeTo(got, want) {
+		t.Errorf("expected %g N, got %g N", want, got)
+	}
+}
+
+func TestForceAtZeroSeparation(t *testing.T) {
+	f := Force(Particle{Charge: 1}, Particle{Charge: -1}, 2)
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		t.Errorf("expected a finite force between coincident particles, got %g N", f)
+	}
+}
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		name           string
+		charge, radius float64
+		want           float64
+	}{
+		{"unit charge at 1 m", 1, 1, K},
+		{"unit charge at 10 m", 1, 10, K / 100},
+		{"negative charge", -0.5, 1, -K / 2},
+	}
+	for _, tt := range tests {
+		if got := Field(tt.charge, tt.radius, 2); !closeTo(got, tt.want) {
+			t.Errorf("%s: expected %g N/C, got %g N/C", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPotential(t *testing.T) {
+	tests := []struct {
+		name                     string
+		charge, radius, exponent float64
+		want                     float64
+	}{
+		{"unit charge at 1 m", 1, 1, 2, K},
+		{"unit charge at 10 m", 1, 10, 2, K / 10},
+		{"negative charge", -0.5, 1, 2, -K / 2},
+		{"inverse cube", 1, 2, 3, K / 8},
+		{"logarithmic", 1, math.E, 1, -K},
+	}
+	for _, tt := range tests {
+		if got := Potential(tt.charge, tt.radius, tt.exponent); !closeTo(got, tt.want) {
+			t.Errorf("%s: expected %g V, got %g V", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestNetForce(t *testing.T) {
+	probe := Particle{Charge: 1}
+	tests := []struct {
+		name   string
+		others []Particle
+		fx, fy float64
+	}{
+		{"nothing", nil, 0, 0},
+		{"repelled to the right", []Particle{{X: -1, Charge: 1}}, K, 0},
+		{"attracted upwards", []Particle{{Y: -1, Charge: -1}}, 0, -K},
+		// midway between the two charges of a dipole both forces point towards the negative one
+		{"dipole midpoint", []Particle{{X: -1, Charge: 1}, {X: 1, Charge: -1}}, 2 * K, 0},
+		// equal charges on both sides cancel each other
+		{"symmetric", []Particle{{X: -1, Charge: 1}, {X: 1, Charge: 1}}, 0, 0},
+		{"coincident is skipped", []Particle{{Charge: 1}, {X: -1, Charge: 1}}, K, 0},
+		{"neutral is skipped", []Particle{{X: -1, Charge: 0}}, 0, 0},
+	}
+	for _, tt := range tests {
+		fx, fy := NetForce(probe, tt.others, 2)
+		if !closeTo(fx, tt.fx) || !closeTo(fy, tt.fy) {
+			t.Errorf("%s: expected (%g, %g) N, got (%g, %g) N", tt.name, tt.fx, tt.fy, fx, fy)
+		}
+	}
+}
+
+func TestNetFieldOfSingleCharge(t *testing.T) {
+	q := Particle{Charge: 1}
+	for _, r := range []float64{0.1, 0.5, 1, 2, 10} {
+		// along both axes and on a diagonal, the field points away from the charge
+		for _, dir := range [][2]float64{{1, 0}, {0, -1}, {math.Sqrt2 / 2, math.Sqrt2 / 2}} {
+			ex, ey := NetField(Particle{X: r * dir[0], Y: r * dir[1]}, []Particle{q}, 2)
+			want := K * q.Charge / (r * r)
+			if !closeTo(ex, want*dir[0]) || !closeTo(ey, want*dir[1]) {
+				t.Errorf("at %g m along (%g, %g): expected (%g, %g) N/C, got (%g, %g) N/C", r, dir[0], dir[1], want*dir[0], want*dir[1], ex, ey)
+			}
+		}
+	}
+}
+
+func TestNetFieldIgnoresProbeCharge(t *testing.T) {
+	others := []Particle{{X: -1, Charge: 1}}
+	ex1, ey1 := NetField(Particle{Charge: -3}, others, 2)
+	ex2, ey2 := NetField(Particle{Charge: 0}, others, 2)
+	if ex1 != ex2 || ey1 != ey2 || !closeTo(ex1, K) {
+		t.Errorf("expected a field of (%g, 0) N/C whatever the probe charge, got (%g, %g) and (%g, %g) N/C", K, ex1, ey1, ex2, ey2)
+	}
+}
+
+func TestNetFieldOfSymmetricCharges(t *testing.T) {
+	equal := []Particle{{X: -1, Charge: 1}, {X: 1, Charge: 1}}
+	dipole := []Particle{{X: -1, Charge: 1}, {X: 1, Charge: -1}}
+
+	// equal charges cancel at the midpoint, and only push away from it along the perpendicular bisector
+	if ex, ey := NetField(Particle{}, equal, 2); !closeTo(ex, 0) || !closeTo(ey, 0) {
+		t.Errorf("equal charges: expected no field at the midpoint, got (%g, %g) N/C", ex, ey)
+	}
+	// a dipole has no field across its axis on the perpendicular bisector, pointing from + to - there,
+	// with a magnitude of 2kq/r² at the midpoint
+	for _, y := range []float64{0, 0.5, 1, 3} {
+		ex, ey := NetField(Particle{Y: y}, equal, 2)
+		if !closeTo(ex, 0) || (y > 0 && ey <= 0) {
+			t.Errorf("equal charges at y = %g m: expected a field along +Y only, got (%g, %g) N/C", y, ex, ey)
+		}
+		ex, ey = NetField(Particle{Y: y}, dipole, 2)
+		r2 := 1 + y*y
+		want := 2 * K / r2 / math.Sqrt(r2)
+		if !closeTo(ex, want) || !closeTo(ey, 0) {
+			t.Errorf("dipole at y = %g m: expected (%g, 0) N/C, got (%g, %g) N/C", y, want, ex, ey)
+		}
+	}
+}
+
+func TestNetForceIgnoresDepthComponent(t *testing.T) {
+	// a charge straight behind the target pushes it only along Z, which is not returned
+	fx, fy := NetForce(Particle{Charge: 1}, []Particle{{Z: 1, Charge: 1}}, 2)
+	if !closeTo(fx, 0) || !closeTo(fy, 0) {
+		t.Errorf("expected no force on the XY plane, got (%g, %g) N", fx, fy)
+	}
+}
@@ -0,0 +1,102 @@
+package charges
+
+import "math"
+
+// cell is the position of a cell of a Grid, in cells
+type cell struct {
+	x, y int
+}
+
+// Grid is a uniform spatial hash of particles, so the net force on a particle only sums exactly the
+// particles closer than Cutoff, the others being replaced by the total charge of their cell.
+// With up to Threshold particles every particle is summed exactly.
+type Grid struct {
+	CellSize  float64 // side of a cell, in m
+	Cutoff    float64 // particles closer than this (in m) are always summed exactly
+	Threshold int     // up to this many particles the net force is exact
+
+	particles []Particle
+	cells     map[cell][]Particle
+	// order lists the cells in the order their first particle was added, so sums don't depend on map order
+	order []cell
+	// totals holds the charge of each cell, placed at the center of the cell charges weighted by their magnitude
+	totals map[cell]Particle
+}
+
+// NewGrid hashes the particles in cells of cellSize m
+func NewGrid(particles []Particle, cellSize, cutoff float64, threshold int) *Grid {
+	g := &Grid{
+		CellSize:  cellSize,
+		Cutoff:    cutoff,
+		Threshold: threshold,
+		particles: particles,
+		cells:     map[cell][]Particle{},
+		totals:    map[cell]Particle{},
+	}
+	if len(particles) <= threshold {
+		return g
+	}
+	weights := map[cell]float64{}
+	for _, p := range particles {
+		c := g.cellOf(p)
+		if _, ok := g.cells[c]; !ok {
+			g.order = append(g.order, c)
+		}
+		g.cells[c] = append(g.cells[c], p)
+		t := g.totals[c]
+		w := math.Abs(p.Charge)
+		t.X += p.X * w
+		t.Y += p.Y * w
+		t.Z += p.Z * w
+		t.Charge += p.Charge
+		g.totals[c] = t
+		weights[c] += w
+	}
+	for c, t := range g.totals {
+		if w := weights[c]; w > 0 {
+			t.X, t.Y, t.Z = t.X/w, t.Y/w, t.Z/w
+		}
+		g.totals[c] = t
+	}
+	return g
+}
+
+// cellOf returns the cell a particle falls in
+func (g *Grid) cellOf(p Particle) cell {
+	return cell{int(math.Floor(p.X / g.CellSize)), int(math.Floor(p.Y / g.CellSize))}
+}
+
+// NetForce sums the forces exerted on target by the particles of the grid, like the NetForce function.
+// target itself may be in the grid, as particles at its position are skipped.
+func (g *Grid) NetForce(target Particle, exponent float64) (fx, fy float64) {
+	if len(g.particles) <= g.Threshold {
+		return NetForce(target, g.particles, exponent)
+	}
+	near := int(math.Ceil(g.Cutoff / g.CellSize))
+	c := g.cellOf(target)
+	for _, other := range g.order {
+		var x, y float64
+		if abs(other.x-c.x) <= near && abs(other.y-c.y) <= near {
+			x, y = NetForce(target, g.cells[other], exponent)
+		} else {
+			x, y = NetForce(target, []Particle{g.totals[other]}, exponent)
+		}
+		fx += x
+		fy += y
+	}
+	return fx, fy
+}
+
+// Field returns the electric field at p (whose charge is ignored), in N/C,
+// which is the force the particles of the grid exert on a 1 C charge there
+func (g *Grid) Field(p Particle, exponent float64) (ex, ey float64) {
+	p.Charge = 1
+	return g.NetForce(p, exponent)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,83 @@
+package charges
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randomParticles spreads n particles with charges between -1 and 1 C over an 8x6 m area
+func randomParticles(n int) []Particle {
+	r := rand.New(rand.NewSource(1))
+	particles := make([]Particle, n)
+	for i := range particles {
+		particles[i] = Particle{X: r.Float64() * 8, Y: r.Float64() * 6, Charge: r.Float64()*2 - 1}
+	}
+	return particles
+}
+
+func TestGridIsExactBelowThreshold(t *testing.T) {
+	particles := randomParticles(50)
+	g := NewGrid(particles, 1, 2, 100)
+	for _, p := range particles {
+		fx, fy := g.NetForce(p, 2)
+		wantX, wantY := NetForce(p, particles, 2)
+		if fx != wantX || fy != wantY {
+			t.Fatalf("expected (%g, %g) N, got (%g, %g) N", wantX, wantY, fx, fy)
+		}
+	}
+}
+
+func TestGridIsExactWithinCutoff(t *testing.T) {
+	// all the particles are within the cutoff of each other
+	particles := randomParticles(50)
+	g := NewGrid(particles, 1, 10, 0)
+	for _, p := range particles {
+		fx, fy := g.NetForce(p, 2)
+		wantX, wantY := NetForce(p, particles, 2)
+		if math.Abs(fx-wantX) > 1e-6*math.Abs(wantX) || math.Abs(fy-wantY) > 1e-6*math.Abs(wantY) {
+			t.Fatalf("expected (%g, %g) N, got (%g, %g) N", wantX, wantY, fx, fy)
+		}
+	}
+}
+
+func TestGridApproximatesFarParticles(t *testing.T) {
+	// a tight cluster of positive charges far away acts like a single charge
+	particles := []Particle{{X: 0.5, Y: 0.5, Charge: 1}}
+	for i := 0; i < 10; i++ {
+		particles = append(particles, Particle{X: 20.1 + float64(i)*0.05, Y: 0.5, Charge: 0.1})
+	}
+	g := NewGrid(particles, 1, 2, 0)
+	fx, fy := g.NetForce(particles[0], 2)
+	wantX, wantY := NetForce(particles[0], particles, 2)
+	if math.Abs(fx-wantX) > 1e-2*math.Abs(wantX) || fy != wantY {
+		t.Errorf("expected about (%g, %g) N, got (%g, %g) N", wantX, wantY, fx, fy)
+	}
+}
+
+func TestGridField(t *testing.T) {
+	g := NewGrid([]Particle{{X: -1, Charge: 1}}, 1, 2, 10)
+	ex, ey := g.Field(Particle{Charge: 5}, 2)
+	if !closeTo(ex, K) || !closeTo(ey, 0) {
+		t.Errorf("expected a field of (%g, 0) N/C, got (%g, %g) N/C", K, ex, ey)
+	}
+}
+
+// benchmarkNetForces computes the net force on each of 1000 particles, as done every tick of the motion
+func benchmarkNetForces(b *testing.B, threshold int) {
+	particles := randomParticles(1000)
+	for i := 0; i < b.N; i++ {
+		g := NewGrid(particles, 1, 2, threshold)
+		for _, p := range particles {
+			g.NetForce(p, 2)
+		}
+	}
+}
+
+func BenchmarkNetForces1000Exact(b *testing.B) {
+	benchmarkNetForces(b, 1000)
+}
+
+func BenchmarkNetForces1000Grid(b *testing.B) {
+	benchmarkNetForces(b, 0)
+}
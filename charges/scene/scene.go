@@ -0,0 +1,90 @@
+// Package scene reads and writes the scenes saved by the game, and computes their forces with the charges package,
+// so scenes can be checked by tools running without a window, like the dump-forces command.
+package scene
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/auyer/electrical-charges/charges"
+)
+
+// DefaultUnitScale is the px on screen for each m the game uses unless told otherwise
+const DefaultUnitScale = 100
+
+// File is the JSON representation of a scene saved to disk
+type File struct {
+	Charges []Charge `json:"charges"`
+	// ChargeStep is the charge in C added or removed by 'P'/'N', missing from older files
+	ChargeStep float64 `json:"chargeStep,omitempty"`
+	// NetForcesOnly is the display mode of the scene, only saved to files by the game
+	// as undoing shouldn't change what is displayed
+	NetForcesOnly bool `json:"netForcesOnly,omitempty"`
+}
+
+// Charge is a charge as stored in a File, its position being the top left corner of its sprite in px
+type Charge struct {
+	Name   string  `json:"name"`
+	X      int     `json:"x"`
+	Y      int     `json:"y"`
+	Z      int     `json:"z,omitempty"`
+	Charge float64 `json:"charge"`
+	// Mass is in kg, missing from older files where all the charges had a mass of 1 kg
+	Mass  float64 `json:"mass,omitempty"`
+	Fixed bool    `json:"fixed,omitempty"`
+	// Mirror is the index in the charges of the other charge of the mirrored pair of this one, if any
+	Mirror *int `json:"mirror,omitempty"`
+}
+
+// Parse decodes a scene from its JSON representation
+func Parse(b []byte) (File, error) {
+	f := File{}
+	err := json.Unmarshal(b, &f)
+	return f, err
+}
+
+// Particles returns the charges of the scene as particles on the plane of the screen, as the game places them
+// without perspective, unitScale px making a m
+func (f File) Particles(unitScale float64) []charges.Particle {
+	particles := make([]charges.Particle, len(f.Charges))
+	for i, c := range f.Charges {
+		particles[i] = charges.Particle{X: float64(c.X) / unitScale, Y: float64(c.Y) / unitScale, Charge: c.Charge}
+	}
+	return particles
+}
+
+// WriteForceMatrix writes the force between every pair of charges of the scene (N) as a tab separated matrix,
+// with the names of the charges as the first row and column, for Coulomb's constant k and the force law
+// falling with the distance raised to exponent. Positive forces are repulsions.
+// The diagonal is 0, and coincident charges, whose force is undefined, get NaN.
+func (f File) WriteForceMatrix(w io.Writer, unitScale, k, exponent float64) error {
+	particles := f.Particles(unitScale)
+	row := []string{""}
+	for _, c := range f.Charges {
+		row = append(row, c.Name)
+	}
+	if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+		return err
+	}
+	for i, p1 := range particles {
+		row = []string{f.Charges[i].Name}
+		for j, p2 := range particles {
+			force := 0.
+			if i != j {
+				force = k / charges.K * charges.Force(p1, p2, exponent)
+				if charges.Coincident(p1, p2) {
+					force = math.NaN()
+				}
+			}
+			row = append(row, strconv.FormatFloat(force, 'g', -1, 64))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package scene
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteForceMatrixMarksCoincidentCharges(t *testing.T) {
+	f := File{Charges: []Charge{{Name: "A", Charge: 1}, {Name: "B", Charge: 1}}}
+	b := &bytes.Buffer{}
+	if err := f.WriteForceMatrix(b, DefaultUnitScale, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\tA\tB\nA\t0\tNaN\nB\tNaN\t0\n"; b.String() != want {
+		t.Errorf("expected %q, got %q", want, b.String())
+	}
+}
+
+func TestParseKeepsMirrors(t *testing.T) {
+	f, err := Parse([]byte(`{"charges": [{"name": "A", "x": 10, "y": 20, "charge": 2, "mirror": 1}, {"name": "B", "mirror": 0}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Charges) != 2 || f.Charges[0].Mirror == nil || *f.Charges[0].Mirror != 1 || f.Charges[1].Mirror == nil || *f.Charges[1].Mirror != 0 {
+		t.Errorf("expected A and B mirroring each other, got %+v", f.Charges)
+	}
+	if p := f.Particles(10); p[0].X != 1 || p[0].Y != 2 || p[0].Charge != 2 {
+		t.Errorf("expected A at (1, 2) m with 2 C, got %+v", p[0])
+	}
+}
@@ -0,0 +1,49 @@
+// Command dump-forces prints the matrix of the forces between the charges of a scene saved by the game,
+// without opening a window, so physics checks can be scripted in CI:
+//
+//	dump-forces [-k K] [-unit-scale PX] [-exponent N] scene.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/auyer/electrical-charges/charges"
+	"github.com/auyer/electrical-charges/charges/scene"
+)
+
+// run parses the arguments (without the program name) and writes the force matrix of the scene to w
+func run(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet("dump-forces", flag.ContinueOnError)
+	k := flags.Float64("k", charges.K, "Coulomb's constant, in Nm²/C² or the units matching -unit-scale")
+	unitScale := flags.Float64("unit-scale", scene.DefaultUnitScale, "px on screen for each unit of length, as given to the game")
+	exponent := flags.Float64("exponent", 2, "the force falls with the distance raised to this, 2 being Coulomb's law")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected a scene file, as saved with 'S', got %d arguments", flags.NArg())
+	}
+	if *unitScale <= 0 {
+		return fmt.Errorf("-unit-scale must be positive, got %g", *unitScale)
+	}
+	b, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	f, err := scene.Parse(b)
+	if err != nil {
+		return fmt.Errorf("could not read the scene: %v", err)
+	}
+	return f.WriteForceMatrix(w, *unitScale, *k, *exponent)
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/auyer/electrical-charges/charges"
+)
+
+func TestDumpForcesWithCoincidentCharges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dump-forces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// Q0 and Q1 share a position, Q2 is 1 m to the right of them
+	path := filepath.Join(dir, "scene.json")
+	scene := `{"charges": [
+		{"name": "Q0", "x": 0, "y": 0, "charge": 1},
+		{"name": "Q1", "x": 0, "y": 0, "charge": -1},
+		{"name": "Q2", "x": 100, "y": 0, "charge": 1}
+	]}`
+	if err := ioutil.WriteFile(path, []byte(scene), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &bytes.Buffer{}
+	if err := run([]string{path}, b); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 4 || lines[0] != "\tQ0\tQ1\tQ2" {
+		t.Fatalf("expected a header and 3 rows, got %q", b.String())
+	}
+	want := [][]float64{
+		{0, math.NaN(), charges.K},
+		{math.NaN(), 0, -charges.K},
+		{charges.K, -charges.K, 0},
+	}
+	for i, line := range lines[1:] {
+		cells := strings.Split(line, "\t")
+		if len(cells) != 4 || cells[0] != "Q"+strconv.Itoa(i) {
+			t.Fatalf("expected row Q%d with 3 forces, got %q", i, line)
+		}
+		for j, cell := range cells[1:] {
+			got, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				t.Fatalf("row %d column %d: %v", i, j, err)
+			}
+			if math.IsNaN(want[i][j]) != math.IsNaN(got) || (!math.IsNaN(got) && math.Abs(got-want[i][j]) > 1e-9*math.Abs(want[i][j])) {
+				t.Errorf("force between Q%d and Q%d: expected %g N, got %g N", i, j, want[i][j], got)
+			}
+		}
+	}
+}
+
+func TestDumpForcesNeedsAScene(t *testing.T) {
+	if err := run(nil, &bytes.Buffer{}); err == nil {
+		t.Errorf("expected an error without a scene file")
+	}
+}
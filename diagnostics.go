@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+// diagnosticsTemplate is the widest line of the diagnostics overlay, used to place it so it doesn't move
+// when the values change width
+const diagnosticsTemplate = "Strokes 00  Motion playing"
+
+// diagnosticsLines returns the lines of the diagnostics overlay
+func diagnosticsLines(g *Game) []string {
+	motion := "paused"
+	if g.playing {
+		motion = "playing"
+	}
+	return []string{
+		fmt.Sprintf("FPS %5.1f  Charges %d", ebiten.CurrentFPS(), len(g.sprites)),
+		fmt.Sprintf("Strokes %d  Motion %s", len(g.strokes), motion),
+	}
+}
+
+// drawDiagnostics draws the diagnostics overlay on the top right, above the readouts.
+// The lines start at the same x every frame, so the values are easy to follow as they change.
+func drawDiagnostics(screen *ebiten.Image, g *Game) {
+	x := g.width - font.MeasureString(g.Font, diagnosticsTemplate).Ceil() - fullScreenWidth*.01
+	y := fullScreenHeight*.05 - fontHeight
+	for _, line := range diagnosticsLines(g) {
+		text.Draw(screen, line, g.Font, x, y, color.White)
+		y += fontHeight + fontHeight/2
+	}
+}
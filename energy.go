@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+)
+
+const (
+	energyPlotSamples = 300 // one sample each tick, 5 s at 60 TPS
+	energyPlotWidth   = 200 // px
+	energyPlotHeight  = 80  // px
+)
+
+// energyHistory is a ring buffer of the last energyPlotSamples values of the potential energy of the system, in J
+type energyHistory struct {
+	samples []float64
+	next    int // where the next sample goes
+	count   int
+}
+
+// add records u, replacing the oldest sample once the buffer is full.
+// The buffer is only allocated the first time.
+func (h *energyHistory) add(u float64) {
+	if h.samples == nil {
+		h.samples = make([]float64, energyPlotSamples)
+	}
+	h.samples[h.next] = u
+	h.next = (h.next + 1) % len(h.samples)
+	if h.count < len(h.samples) {
+		h.count++
+	}
+}
+
+// clear forgets all the samples
+func (h *energyHistory) clear() {
+	h.next, h.count = 0, 0
+}
+
+// values returns the samples, the oldest first
+func (h *energyHistory) values() []float64 {
+	values := make([]float64, 0, h.count)
+	for i := h.count; i > 0; i-- {
+		values = append(values, h.samples[(h.next-i+len(h.samples))%len(h.samples)])
+	}
+	return values
+}
+
+// drawEnergyPlot draws the potential energy of the last seconds of motion as a line graph on the bottom left
+// of the playfield, above the ruler. The graph spans from the lowest to the highest energy recorded,
+// so the exchange with the kinetic energy, or the drift of the integration, is visible at any scale.
+func drawEnergyPlot(screen *ebiten.Image, g *Game) {
+	values := g.energy.values()
+	if len(values) < 2 {
+		return
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, u := range values {
+		low, high = math.Min(low, u), math.Max(high, u)
+	}
+
+	_, height := g.playfield()
+	x := float64(fullScreenWidth * .01)
+	y := float64(height - 4*fontHeight - energyPlotHeight)
+	drawRectangle(screen, x, y, energyPlotWidth, energyPlotHeight, color.NRGBA{0x00, 0x00, 0x00, 0xc0})
+	text.Draw(screen, fmt.Sprintf("U = %.2e J", values[len(values)-1]), g.Font, int(x)+fontHeight/2, int(y)-fontHeight/2, color.White)
+
+	// pointY maps an energy to the plot, the highest at the top; a flat history is drawn in the middle
+	pointY := func(u float64) float64 {
+		if high == low {
+			return y + energyPlotHeight/2
+		}
+		return y + energyPlotHeight*(high-u)/(high-low)
+	}
+	dx := float64(energyPlotWidth) / float64(energyPlotSamples-1)
+	clr := overlayColor(color.NRGBA{0xff, 0xd0, 0x40, 0xff})
+	for i := 1; i < len(values); i++ {
+		drawLine(screen, x+dx*float64(i-1), pointY(values[i-1]), x+dx*float64(i), pointY(values[i]), clr)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// ExportForcesCSV writes one CSV row per pair of charges with their names and charges (C),
+// their separation (m) and force (N) as displayed on screen, and the angle (in degrees) going from the first to the second
+func (g *Game) ExportForcesCSV(w io.Writer) error {
+	c := csv.NewWriter(w)
+	c.Write([]string{"name1", "name2", "charge1 (C)", "charge2 (C)", "separation (m)", "force (N)", "angle (deg)"})
+	for i, s1 := range g.sprites {
+		for _, s2 := range g.sprites[i+1:] {
+			c.Write([]string{
+				s1.name,
+				s2.name,
+				strconv.FormatFloat(s1.charge, 'g', -1, 64),
+				strconv.FormatFloat(s2.charge, 'g', -1, 64),
+				strconv.FormatFloat(distance(s1, s2), 'g', -1, 64),
+				strconv.FormatFloat(force(s1, s2), 'g', -1, 64),
+				strconv.FormatFloat(angle(s2, s1)*180/math.Pi, 'g', -1, 64),
+			})
+		}
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// SaveForcesCSV exports the forces to a CSV file named after the current time, returning the name of the file
+func (g *Game) SaveForcesCSV() (string, error) {
+	b := &bytes.Buffer{}
+	if err := g.ExportForcesCSV(b); err != nil {
+		return "", err
+	}
+	return g.storage.WriteUnique("forces-"+time.Now().Format("20060102-150405"), ".csv", b.Bytes())
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/auyer/electrical-charges/charges"
+	"github.com/hajimehoshi/ebiten"
+)
+
+// fieldAt sums the electric field vectors of all charges at the point (x, y) of the screen
+func fieldAt(g *Game, x, y float64) (ex, ey float64) {
+	// a probe centered on the point, so distances are measured between centers as for the sprites
+	w, h := neutralImage.Size()
+	probe := charges.Particle{X: (x - float64(w/2)) / g.unitScale, Y: (y - float64(h/2)) / g.unitScale}
+	if g.grid != nil {
+		ex, ey := g.grid.Field(probe, theGame.exponent)
+		return g.kScale() * ex, g.kScale() * ey
+	}
+	particles := make([]charges.Particle, len(g.sprites))
+	for i, s := range g.sprites {
+		particles[i] = s.particle()
+	}
+	ex, ey = charges.NetField(probe, particles, g.exponent)
+	return g.kScale() * ex, g.kScale() * ey
+}
+
+// fieldSample is the field computed at a point of the grid
+type fieldSample struct {
+	x, y      int
+	a         float64
+	magnitude float64
+}
+
+// drawFieldGrid samples the field of all charges every spacing px and draws it as a grid of arrows.
+// All arrows have the same length, their color goes from blue for the weakest field to red for the strongest.
+func drawFieldGrid(screen *ebiten.Image, g *Game, spacing int) {
+	samples := []fieldSample{}
+	minMagnitude, maxMagnitude := math.Inf(1), 0.
+	width, height := g.playfield()
+	for y := spacing / 2; y < height; y += spacing {
+		for x := spacing / 2; x < width; x += spacing {
+			if g.spriteAt(x, y) != nil {
+				continue
+			}
+			ex, ey := fieldAt(g, float64(x), float64(y))
+			magnitude := math.Hypot(ex, ey)
+			if magnitude == 0 {
+				continue
+			}
+			samples = append(samples, fieldSample{x, y, math.Atan2(ey, ex), magnitude})
+			minMagnitude = math.Min(minMagnitude, magnitude)
+			maxMagnitude = math.Max(maxMagnitude, magnitude)
+		}
+	}
+
+	length := float64(spacing) * .6
+	for _, s := range samples {
+		// the colors are spread on a log scale, as the field falls quickly with the distance
+		t := 1.
+		if maxMagnitude > minMagnitude {
+			t = math.Log(s.magnitude/minMagnitude) / math.Log(maxMagnitude/minMagnitude)
+		}
+		clr := color.NRGBA{uint8(0xff * t), 0x40, uint8(0xff * (1 - t)), 0xff}
+		x := float64(s.x) - length/2*math.Cos(s.a)
+		y := float64(s.y) - length/2*math.Sin(s.a)
+		drawArrow(screen, x, y, s.a, length, overlayColor(clr))
+	}
+}
+
+// fieldLineCount returns how many field lines start from a charge, proportional to its magnitude.
+// Neutral charges have none, any other charge at least one.
+func fieldLineCount(charge float64) int {
+	if charge == 0 {
+		return 0
+	}
+	n := int(math.Round(math.Abs(charge) * fieldLinesPerCoulomb))
+	return int(math.Max(1, math.Min(float64(n), maxFieldLines)))
+}
+
+// drawFieldLines draws the field lines starting evenly around each positive charge and following the field
+// until they reach a negative charge or leave the playfield.
+// Without positive charges the lines start from the negative ones and go against the field instead,
+// as they do from every mass in gravity mode.
+func drawFieldLines(screen *ebiten.Image, g *Game) {
+	sign := 1.
+	sources := []*Sprite{}
+	for _, s := range g.sprites {
+		if s.charge > 0 && !g.gravity {
+			sources = append(sources, s)
+		}
+	}
+	if g.gravity {
+		sign = -1
+		for _, s := range g.sprites {
+			if s.charge != 0 {
+				sources = append(sources, s)
+			}
+		}
+	} else if len(sources) == 0 {
+		sign = -1
+		for _, s := range g.sprites {
+			if s.charge < 0 {
+				sources = append(sources, s)
+			}
+		}
+	}
+
+	clr := overlayColor(fade(color.White, .6))
+	for _, s := range sources {
+		n := fieldLineCount(s.charge)
+		cx, cy := s.center()
+		radius := s.radius()
+		for i := 0; i < n; i++ {
+			a := 2 * math.Pi * float64(i) / float64(n)
+			drawFieldLine(screen, g, cx+radius*math.Cos(a), cy+radius*math.Sin(a), sign, s, clr)
+		}
+	}
+}
+
+// drawFieldLine integrates a single field line from (x, y), moving along the field if sign is 1 or against it if -1.
+// It stops when reaching a charge of the opposite sign to source, leaving the playfield or after fieldLineMaxSteps.
+func drawFieldLine(screen *ebiten.Image, g *Game, x, y, sign float64, source *Sprite, clr color.Color) {
+	width, height := g.playfield()
+	// direction returns the unit vector along the field at (x, y), or false where the field vanishes
+	direction := func(x, y float64) (float64, float64, bool) {
+		ex, ey := fieldAt(g, x, y)
+		magnitude := math.Hypot(ex, ey)
+		if magnitude == 0 || math.IsNaN(magnitude) || math.IsInf(magnitude, 0) {
+			return 0, 0, false
+		}
+		return sign * ex / magnitude, sign * ey / magnitude, true
+	}
+
+	for i := 0; i < fieldLineMaxSteps; i++ {
+		// midpoint method, so the lines keep curving smoothly around the charges
+		dx, dy, ok := direction(x, y)
+		if !ok {
+			return
+		}
+		dx, dy, ok = direction(x+dx*fieldLineStep/2, y+dy*fieldLineStep/2)
+		if !ok {
+			return
+		}
+		nx, ny := x+dx*fieldLineStep, y+dy*fieldLineStep
+		drawLine(screen, x, y, nx, ny, clr)
+		x, y = nx, ny
+
+		if x < 0 || y < 0 || x > float64(width) || y > float64(height) {
+			return
+		}
+		for _, s := range g.sprites {
+			if s == source || s.charge*source.charge >= 0 {
+				continue
+			}
+			if cx, cy := s.center(); math.Hypot(x-cx, y-cy) < s.radius() {
+				return
+			}
+		}
+	}
+}
+
+// potentialAt sums the electric potential of all charges at the point (x, y) of the screen
+func potentialAt(g *Game, x, y int) float64 {
+	w, h := neutralImage.Size()
+	probe := &Sprite{x: x - w/2, y: y - h/2}
+	v := 0.
+	for _, s := range g.sprites {
+		v += potential(s.source(), distance(probe, s))
+	}
+	return v
+}
+
+// defaultPotentialLevels returns the potentials drawn as equipotentials: the potential 1 m away from a 0.1 C charge
+// multiplied by powers of 2, both positive and negative
+func defaultPotentialLevels() []float64 {
+	reference := potential(0.1, 1)
+	levels := []float64{}
+	for i := -2; i <= 4; i++ {
+		levels = append(levels, reference*math.Pow(2, float64(i)), -reference*math.Pow(2, float64(i)))
+	}
+	return levels
+}
+
+// drawEquipotentials samples the potential of all charges on a coarse grid and draws the contour lines
+// at the requested potential levels (in V) using marching squares.
+// Positive potentials are drawn in warm colors and negative ones in cool colors.
+func drawEquipotentials(screen *ebiten.Image, g *Game, levels []float64) {
+	step := equipotentialSpacing
+	width, height := g.playfield()
+	cols, rows := width/step+1, height/step+1
+	grid := make([][]float64, rows)
+	for j := range grid {
+		grid[j] = make([]float64, cols)
+		for i := range grid[j] {
+			grid[j][i] = potentialAt(g, i*step, j*step)
+		}
+	}
+
+	for _, level := range levels {
+		clr := color.Color(color.NRGBA{0xff, 0x90, 0x30, 0xff})
+		if level < 0 {
+			clr = color.NRGBA{0x40, 0x90, 0xff, 0xff}
+		}
+		clr = overlayColor(clr)
+		for j := 0; j < rows-1; j++ {
+			for i := 0; i < cols-1; i++ {
+				drawContourCell(screen, grid, i, j, step, level, clr)
+			}
+		}
+	}
+}
+
+// drawContourCell draws the part of the contour at level crossing the grid cell whose top left corner is (i, j)
+func drawContourCell(screen *ebiten.Image, grid [][]float64, i, j, step int, level float64, clr color.Color) {
+	x, y := float64(i*step), float64(j*step)
+	s := float64(step)
+	// corners in clockwise order starting at the top left
+	v := [4]float64{grid[j][i], grid[j][i+1], grid[j+1][i+1], grid[j+1][i]}
+	cx := [4]float64{x, x + s, x + s, x}
+	cy := [4]float64{y, y, y + s, y + s}
+
+	// crossing returns where the contour crosses the edge going from corner a to corner b
+	crossing := func(a, b int) (float64, float64) {
+		t := (level - v[a]) / (v[b] - v[a])
+		return cx[a] + t*(cx[b]-cx[a]), cy[a] + t*(cy[b]-cy[a])
+	}
+
+	// edges crossed by the contour, edge e goes from corner e to corner e+1
+	crossed := []int{}
+	for e := 0; e < 4; e++ {
+		if (v[e] >= level) != (v[(e+1)%4] >= level) {
+			crossed = append(crossed, e)
+		}
+	}
+	switch len(crossed) {
+	case 2:
+		x1, y1 := crossing(crossed[0], (crossed[0]+1)%4)
+		x2, y2 := crossing(crossed[1], (crossed[1]+1)%4)
+		drawLine(screen, x1, y1, x2, y2, clr)
+	case 4:
+		// saddle: the value at the center decides which corners are connected
+		center := (v[0] + v[1] + v[2] + v[3]) / 4
+		pairs := [][2]int{{0, 3}, {1, 2}}
+		if (center >= level) == (v[0] >= level) {
+			// corners 0 and 2 are joined through the center, cutting off corners 1 and 3
+			pairs = [][2]int{{0, 1}, {2, 3}}
+		}
+		for _, p := range pairs {
+			x1, y1 := crossing(p[0], (p[0]+1)%4)
+			x2, y2 := crossing(p[1], (p[1]+1)%4)
+			drawLine(screen, x1, y1, x2, y2, clr)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/auyer/electrical-charges/charges"
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+)
+
+// forceArrowWidth is the width in px of the net force arrows, drawn thicker than the field arrows
+const forceArrowWidth = 3
+
+var (
+	// colors of the net force arrows, from the weakest force to the strongest
+	weakForceColor   = color.NRGBA{0x60, 0xff, 0x60, 0xff}
+	strongForceColor = color.NRGBA{0xff, 0x30, 0xff, 0xff}
+	// subsetForceColor is the color of the force exerted by the selected charges only
+	subsetForceColor = color.NRGBA{0xff, 0xe0, 0x40, 0xff}
+)
+
+// drawThickArrow draws an arrow like drawArrow, made of width parallel arrows 1 px apart
+func drawThickArrow(screen *ebiten.Image, x, y, a, length float64, width int, clr color.Color) {
+	for i := 0; i < width; i++ {
+		// offset perpendicular to the arrow, centered on (x, y)
+		o := float64(i) - float64(width-1)/2
+		drawArrow(screen, x-o*math.Sin(a), y+o*math.Cos(a), a, length, clr)
+	}
+}
+
+// forceArrow is the net force on a sprite drawn as an arrow from its center, at angle a (in rads)
+type forceArrow struct {
+	s         *Sprite
+	a         float64
+	magnitude float64
+	length    float64
+	color     color.Color
+}
+
+// forceArrows returns the net force arrows of every charge that can move except skipped, colored from
+// weakForceColor for the weakest force to strongForceColor for the strongest.
+// The pinned charges have theirs too in net forces only mode, where nothing else shows their force.
+func forceArrows(g *Game, skipped *Sprite) []forceArrow {
+	arrows := []forceArrow{}
+	minMagnitude, maxMagnitude := math.Inf(1), 0.
+	for _, s := range g.sprites {
+		if s == skipped || s.fixed && !g.netForcesOnly {
+			continue
+		}
+		fx, fy := netForce(g, s)
+		magnitude := math.Hypot(fx, fy)
+		if magnitude == 0 {
+			continue
+		}
+		arrows = append(arrows, forceArrow{s: s, a: math.Atan2(fy, fx), magnitude: magnitude, length: arrowLength(magnitude)})
+		minMagnitude = math.Min(minMagnitude, magnitude)
+		maxMagnitude = math.Max(maxMagnitude, magnitude)
+	}
+
+	for i, ar := range arrows {
+		// the colors are spread on a log scale, like the lengths
+		t := 1.
+		if maxMagnitude > minMagnitude {
+			t = math.Log(ar.magnitude/minMagnitude) / math.Log(maxMagnitude/minMagnitude)
+		}
+		arrows[i].color = color.NRGBA{
+			uint8(float64(weakForceColor.R) + t*(float64(strongForceColor.R)-float64(weakForceColor.R))),
+			uint8(float64(weakForceColor.G) + t*(float64(strongForceColor.G)-float64(weakForceColor.G))),
+			uint8(float64(weakForceColor.B) + t*(float64(strongForceColor.B)-float64(weakForceColor.B))),
+			0xff,
+		}
+	}
+	return arrows
+}
+
+// drawForceArrows draws the net force arrows of forceArrows as thick arrows.
+// The chosen sprite is skipped, as its net force is already drawn with its value.
+func drawForceArrows(screen *ebiten.Image, g *Game) {
+	for _, ar := range forceArrows(g, g.ChosenSprite) {
+		x, y := ar.s.center()
+		drawThickArrow(screen, x, y, ar.a, ar.length, forceArrowWidth, overlayColor(ar.color))
+	}
+}
+
+// subsetForce sums the forces exerted on target by the other selected sprites only, ignoring the rest,
+// so the contribution of a group of charges can be told apart from the net force.
+// It reports false when no other sprite is selected or when all the others are, as it is then the net force.
+func subsetForce(g *Game, target *Sprite) (fx, fy float64, ok bool) {
+	others := []charges.Particle{}
+	for _, s := range g.selectedSprites() {
+		if s != target {
+			others = append(others, s.particle())
+		}
+	}
+	if len(others) == 0 || len(others) == len(g.sprites)-1 {
+		return 0, 0, false
+	}
+	fx, fy = charges.NetForce(target.particle(), others, g.exponent)
+	return g.kScale() * fx, g.kScale() * fy, true
+}
+
+// drawSubsetForce draws the force the other selected sprites exert on s, as a thick arrow from its center
+// with its magnitude by the tip, to tell it from the thin arrow of the net force
+func drawSubsetForce(screen *ebiten.Image, g *Game, s *Sprite) {
+	fx, fy, ok := subsetForce(g, s)
+	magnitude := math.Hypot(fx, fy)
+	if !ok || magnitude == 0 {
+		return
+	}
+	x, y := s.center()
+	a, length := math.Atan2(fy, fx), arrowLength(magnitude)
+	drawThickArrow(screen, x, y, a, length, forceArrowWidth, overlayColor(subsetForceColor))
+	str, _ := formatValue("%.2e N from the selection", magnitude)
+	clr := color.Color(subsetForceColor)
+	if offScale(magnitude) {
+		clr = offScaleColor
+	}
+	text.Draw(screen, str, g.Font, int(x+length*math.Cos(a)), int(y+length*math.Sin(a))+fontHeight, overlayColor(clr))
+}
+
+// netForcesReadout tells the pairwise values are hidden, so they aren't thought missing
+func netForcesReadout(g *Game) coloredText {
+	if !g.netForcesOnly {
+		return coloredText{}
+	}
+	return coloredText{"Net forces only ('U')", weakForceColor}
+}
+
+// rotation returns the angle in rads the image of the sprite is turned by when drawn, so its right side points
+// along the net force on it while rotateSprites is on. Sprites without a net force are not turned.
+func (s *Sprite) rotation() float64 {
+	if !theGame.rotateSprites {
+		return 0
+	}
+	fx, fy := netForce(theGame, s)
+	if fx == 0 && fy == 0 {
+		return 0
+	}
+	return math.Atan2(fy, fx)
+}
@@ -4,6 +4,7 @@ go 1.12
 
 require (
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
+	github.com/gopherjs/gopherwasm v1.1.0
 	github.com/hajimehoshi/ebiten v1.9.3
 	golang.org/x/image v0.0.0-20190118043309-183bebdce1b2
 )
@@ -0,0 +1,24 @@
+package main
+
+import "math"
+
+// In gravity mode the charges are read as masses, in kg: the same inverse square law then makes them all attract
+// each other, like Newton's gravitation. The force law keeps its constant k, which can be set to
+// G = 6.674e-11 Nm²/kg² with -k for realistic values, at the price of very slow motion.
+
+// source returns what the sprite generates its field with: its charge, or in gravity mode the magnitude
+// of its charge read as a mass
+func (s *Sprite) source() float64 {
+	if theGame.gravity {
+		return math.Abs(s.charge)
+	}
+	return s.charge
+}
+
+// gravityReadout tells the gravity mode is on, as all the values on screen change their meaning
+func gravityReadout(g *Game) coloredText {
+	if !g.gravity {
+		return coloredText{}
+	}
+	return coloredText{"Gravity mode ('F10')", mirrorColor}
+}
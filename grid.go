@@ -0,0 +1,45 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// snap rounds a coordinate to the nearest multiple of size
+func snap(v, size int) int {
+	if v < 0 {
+		return -snap(-v, size)
+	}
+	return (v + size/2) / size * size
+}
+
+// snapSprite moves a sprite so its center lies on the nearest grid point, clamped by MoveBy
+func (g *Game) snapSprite(s *Sprite) {
+	w, h := s.image.Size()
+	cx, cy := s.x+w/2, s.y+h/2
+	s.MoveBy(snap(cx, g.gridSize)-cx, snap(cy, g.gridSize)-cy)
+}
+
+// resizeGrid changes the grid size by delta, keeping it between minGridSize and maxGridSize
+func (g *Game) resizeGrid(delta int) {
+	g.gridSize += delta
+	if g.gridSize < minGridSize {
+		g.gridSize = minGridSize
+	}
+	if g.gridSize > maxGridSize {
+		g.gridSize = maxGridSize
+	}
+}
+
+// drawGrid draws faint lines through the points the charges snap to
+func drawGrid(screen *ebiten.Image, g *Game) {
+	width, height := g.playfield()
+	clr := color.NRGBA{0xff, 0xff, 0xff, 0x30}
+	for x := g.gridSize; x < width; x += g.gridSize {
+		drawLine(screen, float64(x), 0, float64(x), float64(height), clr)
+	}
+	for y := g.gridSize; y < height; y += g.gridSize {
+		drawLine(screen, 0, float64(y), float64(width), float64(y), clr)
+	}
+}
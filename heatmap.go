@@ -0,0 +1,108 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// fieldHeatmap is the field magnitude drawn as an image, kept until the charges change
+type fieldHeatmap struct {
+	image *ebiten.Image
+	// key holds everything the field depends on when the image was computed
+	key []float64
+}
+
+// heatmapKey lists the state the field of the game depends on, so the heatmap is only recomputed when it changes
+func heatmapKey(g *Game) []float64 {
+	width, height := g.playfield()
+	perspective := 0.
+	if g.perspective {
+		perspective = 1
+	}
+	key := []float64{float64(width), float64(height), perspective, g.exponent, g.kScale(), g.unitScale}
+	for _, s := range g.sprites {
+		key = append(key, float64(s.x), float64(s.y), float64(s.z), s.charge)
+	}
+	return key
+}
+
+// sameKey reports whether two heatmap keys are equal
+func sameKey(k1, k2 []float64) bool {
+	if len(k1) != len(k2) {
+		return false
+	}
+	for i := range k1 {
+		if k1[i] != k2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// heatmapPixels samples the field magnitude at the center of each cell of a cols x rows grid covering the playfield
+// and returns the RGBA pixels of an image with one pixel per cell.
+// The colors go from dark blue for the weakest field to yellow for the strongest on a log scale.
+func heatmapPixels(g *Game, cols, rows int) []byte {
+	width, height := g.playfield()
+	magnitudes := make([]float64, cols*rows)
+	minMagnitude, maxMagnitude := math.Inf(1), math.Inf(-1)
+	for j := 0; j < rows; j++ {
+		for i := 0; i < cols; i++ {
+			x := (float64(i) + .5) * float64(width) / float64(cols)
+			y := (float64(j) + .5) * float64(height) / float64(rows)
+			m := math.Log(math.Hypot(fieldAt(g, x, y)))
+			magnitudes[j*cols+i] = m
+			if !math.IsInf(m, 0) && !math.IsNaN(m) {
+				minMagnitude = math.Min(minMagnitude, m)
+				maxMagnitude = math.Max(maxMagnitude, m)
+			}
+		}
+	}
+
+	pixels := make([]byte, 4*cols*rows)
+	for i, m := range magnitudes {
+		if math.IsInf(m, -1) || math.IsNaN(m) || math.IsInf(minMagnitude, 0) {
+			// no field at all, left transparent
+			continue
+		}
+		t := 1.
+		if maxMagnitude > minMagnitude {
+			t = math.Min(math.Max((m-minMagnitude)/(maxMagnitude-minMagnitude), 0), 1)
+		}
+		// dark blue to red for the first half, red to yellow for the second one
+		r, gr, b := math.Min(2*t, 1), math.Max(2*t-1, 0), .5*(1-t)
+		pixels[4*i] = uint8(0xff * r)
+		pixels[4*i+1] = uint8(0xff * gr)
+		pixels[4*i+2] = uint8(0xff * b)
+		pixels[4*i+3] = 0xff
+	}
+	return pixels
+}
+
+// drawFieldHeatmap draws the magnitude of the field over the whole playfield, sampled every heatmapCellSize px
+// and smoothed when scaled to the screen. The samples are only computed again when the charges change.
+func drawFieldHeatmap(screen *ebiten.Image, g *Game) {
+	width, height := g.playfield()
+	cols, rows := (width+heatmapCellSize-1)/heatmapCellSize, (height+heatmapCellSize-1)/heatmapCellSize
+	if cols <= 0 || rows <= 0 {
+		return
+	}
+	key := heatmapKey(g)
+	if g.heatmap == nil || !sameKey(g.heatmap.key, key) {
+		if g.heatmap != nil {
+			g.heatmap.image.Dispose()
+		}
+		img, err := ebiten.NewImage(cols, rows, ebiten.FilterLinear)
+		if err != nil {
+			return
+		}
+		img.ReplacePixels(heatmapPixels(g, cols, rows))
+		g.heatmap = &fieldHeatmap{image: img, key: key}
+	}
+
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(float64(width)/float64(cols), float64(height)/float64(rows))
+	opts.ColorM.Scale(1, 1, 1, heatmapOpacity*g.overlayOpacity)
+	screen.DrawImage(g.heatmap.image, opts)
+}
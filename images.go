@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// loadSpriteImages replaces the embedded charge images by positive.png, negative.png and neutral.png from dir.
+// The three images must decode and have the same size, otherwise the embedded ones are kept.
+func loadSpriteImages(dir string) error {
+	names := []string{"positive.png", "negative.png", "neutral.png"}
+	images := make([]image.Image, len(names))
+	for i, name := range names {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		img, _, err := image.Decode(bytes.NewReader(b))
+		if err != nil {
+			return fmt.Errorf("could not decode %s: %v", name, err)
+		}
+		if size, first := img.Bounds().Size(), images[0]; first != nil && size != first.Bounds().Size() {
+			return fmt.Errorf("%s is %v px but %s is %v px, all the images must have the same size", name, size, names[0], first.Bounds().Size())
+		}
+		images[i] = img
+	}
+	positiveImage, _ = ebiten.NewImageFromImage(images[0], ebiten.FilterDefault)
+	negativeImage, _ = ebiten.NewImageFromImage(images[1], ebiten.FilterDefault)
+	neutralImage, _ = ebiten.NewImageFromImage(images[2], ebiten.FilterDefault)
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/inpututil"
+	"github.com/hajimehoshi/ebiten/text"
+)
+
+// inputMode represents what the text typed by the user will be assigned to
+type inputMode int
+
+const (
+	inputNone inputMode = iota
+	inputPosition
+	inputCharge
+	inputName
+	inputMass
+	inputLine
+	inputCapacitor
+)
+
+// maxNameLength is the longest name that can be typed for a charge, in characters
+const maxNameLength = 16
+
+// inputPrompts holds the label drawn before the text being typed for each input mode
+var inputPrompts = map[inputMode]string{
+	inputPosition:  "x,y (px)",
+	inputCharge:    "Charge (C)",
+	inputName:      "Name",
+	inputMass:      "Mass (kg)",
+	inputLine:      "Line of charges: count,charge (C)",
+	inputCapacitor: "Capacitor: charges per plate,gap,spacing (px),charge (C)",
+}
+
+// startInput starts capturing the keyboard text for the given mode
+func (g *Game) startInput(mode inputMode) {
+	g.inputMode = mode
+	g.inputBuffer = ""
+}
+
+// stopInput leaves the text input mode, discarding the buffer
+func (g *Game) stopInput() {
+	g.inputMode = inputNone
+	g.inputBuffer = ""
+}
+
+// updateInput captures the typed characters while an input mode is active.
+// Enter commits the text, Escape cancels and Backspace erases the last character.
+func (g *Game) updateInput() {
+	for _, r := range ebiten.InputChars() {
+		if g.inputMode == inputName {
+			if unicode.IsPrint(r) && utf8.RuneCountInString(g.inputBuffer) < maxNameLength {
+				g.inputBuffer += string(r)
+			}
+		} else if (r >= '0' && r <= '9') || r == '-' || r == '.' || r == ',' {
+			g.inputBuffer += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.inputBuffer) > 0 {
+		_, size := utf8.DecodeLastRuneInString(g.inputBuffer)
+		g.inputBuffer = g.inputBuffer[:len(g.inputBuffer)-size]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.stopInput()
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+		g.commitInput()
+	}
+}
+
+// commitInput assigns the typed text to the chosen sprite (or all the selected sprites for the charge),
+// or creates the line of charges or the capacitor it describes.
+// Malformed text is ignored, keeping the input open so it can be fixed.
+func (g *Game) commitInput() {
+	if g.inputMode == inputLine {
+		n, q, err := parseLine(g.inputBuffer)
+		if err != nil {
+			return
+		}
+		g.CreateLine(n, g.capCharge(q))
+		g.stopInput()
+		return
+	}
+	if g.inputMode == inputCapacitor {
+		n, gap, spacing, q, err := parseCapacitor(g.inputBuffer)
+		if err != nil {
+			return
+		}
+		g.CreateCapacitor(n, gap, spacing, g.capCharge(q))
+		g.stopInput()
+		return
+	}
+	s := g.ChosenSprite
+	if s == nil {
+		g.stopInput()
+		return
+	}
+	switch g.inputMode {
+	case inputPosition:
+		x, y, err := parsePosition(g.inputBuffer)
+		if err != nil {
+			return
+		}
+		// moving from the origin reuses the clamping done by MoveBy
+		s.x, s.y = 0, 0
+		s.MoveBy(x, y)
+	case inputCharge:
+		charge, err := strconv.ParseFloat(g.inputBuffer, 64)
+		if err != nil {
+			return
+		}
+		charge = g.capCharge(charge)
+		for _, s := range g.selectedSprites() {
+			s.charge = charge
+		}
+	case inputMass:
+		// a charge without mass would get an infinite acceleration
+		mass, err := strconv.ParseFloat(g.inputBuffer, 64)
+		if err != nil || mass <= 0 {
+			return
+		}
+		for _, s := range g.selectedSprites() {
+			s.mass = mass
+		}
+	case inputName:
+		s.name = strings.TrimSpace(g.inputBuffer)
+		if s.name == "" {
+			s.name = g.autoName(s)
+		}
+	}
+	g.stopInput()
+}
+
+// parsePosition parses a "x,y" pair of integer pixel coordinates
+func parsePosition(str string) (int, int, error) {
+	parts := strings.Split(str, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected x,y but got %q", str)
+	}
+	x, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	y, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, err
+	}
+	return x, y, nil
+}
+
+// parseLine parses a "count,charge" pair, the count being between 1 and maxLineCharges
+func parseLine(str string) (int, float64, error) {
+	parts := strings.Split(str, ",")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected count,charge but got %q", str)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+	if n < 1 || n > maxLineCharges {
+		return 0, 0, fmt.Errorf("expected between 1 and %d charges, got %d", maxLineCharges, n)
+	}
+	q, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return n, q, nil
+}
+
+// parseCapacitor parses a "count,gap,spacing,charge" list, the count of charges per plate being between 1 and
+// maxLineCharges and the gap and spacing positive numbers of px
+func parseCapacitor(str string) (n, gap, spacing int, q float64, err error) {
+	parts := strings.Split(str, ",")
+	if len(parts) != 4 {
+		return 0, 0, 0, 0, fmt.Errorf("expected count,gap,spacing,charge but got %q", str)
+	}
+	ints := make([]int, 3)
+	for i := range ints {
+		if ints[i], err = strconv.Atoi(strings.TrimSpace(parts[i])); err != nil {
+			return 0, 0, 0, 0, err
+		}
+	}
+	n, gap, spacing = ints[0], ints[1], ints[2]
+	if n < 1 || n > maxLineCharges {
+		return 0, 0, 0, 0, fmt.Errorf("expected between 1 and %d charges, got %d", maxLineCharges, n)
+	}
+	if gap <= 0 || spacing <= 0 {
+		return 0, 0, 0, 0, fmt.Errorf("expected a positive gap and spacing, got %d and %d", gap, spacing)
+	}
+	if q, err = strconv.ParseFloat(strings.TrimSpace(parts[3]), 64); err != nil {
+		return 0, 0, 0, 0, err
+	}
+	return n, gap, spacing, q, nil
+}
+
+// autoName returns the name a sprite gets when it isn't given one, from its position in g.sprites
+func (g *Game) autoName(s *Sprite) string {
+	for i, ss := range g.sprites {
+		if ss == s {
+			return "Q" + strconv.Itoa(i)
+		}
+	}
+	return "Q"
+}
+
+// duplicateNames returns the names shared by more than one sprite, in the order they first appear
+func (g *Game) duplicateNames() []string {
+	count := map[string]int{}
+	duplicates := []string{}
+	for _, s := range g.sprites {
+		count[s.name]++
+		if count[s.name] == 2 {
+			duplicates = append(duplicates, s.name)
+		}
+	}
+	return duplicates
+}
+
+// duplicateNamesReadout warns when several sprites share a name, as they are allowed to
+func duplicateNamesReadout(g *Game) coloredText {
+	duplicates := g.duplicateNames()
+	if len(duplicates) == 0 {
+		return coloredText{}
+	}
+	return coloredText{"Duplicate names: " + strings.Join(duplicates, ", "), offScaleColor}
+}
+
+// drawInput draws the text being typed above the chosen sprite,
+// or on the bottom left of the playfield when it isn't about a sprite
+func (g *Game) drawInput(screen *ebiten.Image) {
+	str := fmt.Sprintf("%s: %s_", inputPrompts[g.inputMode], g.inputBuffer)
+	switch {
+	case g.inputMode == inputNone:
+	case g.inputMode == inputLine || g.inputMode == inputCapacitor:
+		_, height := g.playfield()
+		text.Draw(screen, str, theGame.Font, fullScreenWidth*.01, height-3*fontHeight, color.White)
+	case g.ChosenSprite != nil:
+		s := g.ChosenSprite
+		text.Draw(screen, str, theGame.Font, s.x, s.y-fontHeight-fontHeight/2, color.White)
+	}
+}
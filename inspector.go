@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+// inspectorColumns are the titles of the columns of the inspector, with a template as wide as their widest value
+// (the name column being as wide as the longest name)
+var inspectorColumns = []struct {
+	title, template string
+}{
+	{"Name", ""},
+	{"x (m)", "-00.00"},
+	{"y (m)", "-00.00"},
+	{"q (C)", "-0.00e-00"},
+	{"|F| (N)", "0.00e+00"},
+}
+
+// inspectorRow is the object dragged by a press on the inspector, s being the charge of the row pressed, if any
+type inspectorRow struct {
+	s *Sprite
+}
+
+// inspectorLayout returns the position of the inspector on the right of the playfield, below the readouts,
+// the left of each column, its width and how many rows of charges fit in it
+func (g *Game) inspectorLayout() (x, y int, columns []int, width, visible int) {
+	margin := fontHeight / 2
+	widths := make([]int, len(inspectorColumns))
+	for i, c := range inspectorColumns {
+		widths[i] = font.MeasureString(g.Font, c.title).Ceil()
+		if w := font.MeasureString(g.Font, c.template).Ceil(); w > widths[i] {
+			widths[i] = w
+		}
+	}
+	for _, s := range g.sprites {
+		if w := font.MeasureString(g.Font, s.name).Ceil(); w > widths[0] {
+			widths[0] = w
+		}
+	}
+	width = margin
+	for _, w := range widths {
+		columns = append(columns, width)
+		width += w + fontHeight
+	}
+	width += margin - fontHeight
+
+	lineHeight := fontHeight + fontHeight/2
+	_, playfieldHeight := g.playfield()
+	y = panelTop(g)
+	// the header and as many rows as fit above the texts drawn at the bottom of the playfield
+	visible = (playfieldHeight-3*fontHeight-y-margin)/lineHeight - 1
+	if visible > len(g.sprites) {
+		visible = len(g.sprites)
+	}
+	x = g.width - width - fullScreenWidth*.01
+	return x, y, columns, width, visible
+}
+
+// inspectorHeight returns the height of the inspector showing visible rows
+func inspectorHeight(visible int) int {
+	return (visible+1)*(fontHeight+fontHeight/2) + fontHeight/2
+}
+
+// inspectorRowAt returns the charge of the row of the inspector at (x, y), if any.
+// inside is false if (x, y) is out of the inspector, or if it is hidden.
+func (g *Game) inspectorRowAt(x, y int) (s *Sprite, inside bool) {
+	if !g.showInspector {
+		return nil, false
+	}
+	left, top, _, width, visible := g.inspectorLayout()
+	if x < left || x >= left+width || y < top || y >= top+inspectorHeight(visible) {
+		return nil, false
+	}
+	// the first line is the header
+	row := (y-top-fontHeight/4)/(fontHeight+fontHeight/2) - 1
+	if row < 0 || row >= visible || g.inspectorScroll+row >= len(g.sprites) {
+		return nil, true
+	}
+	return g.sprites[g.inspectorScroll+row], true
+}
+
+// updateInspector scrolls the inspector by a row for each step of the mouse wheel over it,
+// keeping the last rows at the bottom when charges are deleted
+func (g *Game) updateInspector() {
+	if !g.showInspector {
+		return
+	}
+	if _, dy := ebiten.Wheel(); dy != 0 {
+		if _, inside := g.inspectorRowAt(ebiten.CursorPosition()); inside {
+			// the wheel goes up with a positive offset, showing the rows above
+			g.inspectorScroll -= int(math.Copysign(math.Ceil(math.Abs(dy)), dy))
+		}
+	}
+	_, _, _, _, visible := g.inspectorLayout()
+	if max := len(g.sprites) - visible; g.inspectorScroll > max {
+		g.inspectorScroll = max
+	}
+	if g.inspectorScroll < 0 {
+		g.inspectorScroll = 0
+	}
+}
+
+// drawInspector draws the table of the charges, with their position, charge and net force, the chosen one highlighted
+func drawInspector(screen *ebiten.Image, g *Game) {
+	x, y, columns, width, visible := g.inspectorLayout()
+	lineHeight := fontHeight + fontHeight/2
+	drawRectangle(screen, float64(x), float64(y), float64(width), float64(inspectorHeight(visible)), color.NRGBA{0x00, 0x00, 0x00, 0xc0})
+	for i, c := range inspectorColumns {
+		text.Draw(screen, c.title, g.Font, x+columns[i], y+lineHeight, color.White)
+	}
+	for row, s := range g.sprites[g.inspectorScroll : g.inspectorScroll+visible] {
+		top := y + (row+1)*lineHeight + fontHeight/4
+		if s == g.ChosenSprite {
+			drawRectangle(screen, float64(x), float64(top), float64(width), float64(lineHeight), fade(selectionColor, .4))
+		}
+		p := s.particle()
+		fx, fy := netForce(g, s)
+		forceText, forceColor := formatValue("%.2e", math.Hypot(fx, fy))
+		cells := []string{s.name, fmt.Sprintf("%.2f", p.X), fmt.Sprintf("%.2f", p.Y), fmt.Sprintf("%.2e", s.charge), forceText}
+		for i, cell := range cells {
+			clr := color.Color(color.White)
+			if i == len(cells)-1 {
+				clr = forceColor
+			}
+			text.Draw(screen, cell, g.Font, x+columns[i], top+lineHeight-fontHeight/4, clr)
+		}
+	}
+}
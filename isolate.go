@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+// isolateFontScale is how much larger than the other texts the readout of the isolated pair is
+const isolateFontScale = 1.5
+
+// toggleIsolate isolates the two selected sprites, or goes back to the normal view if a pair is isolated
+func (g *Game) toggleIsolate() {
+	if g.isolated != nil {
+		g.isolated = nil
+		return
+	}
+	if selected := g.selectedSprites(); len(selected) == 2 {
+		g.isolated = selected
+	}
+}
+
+// isolateReadout returns the lines detailing the interaction of the isolated pair
+func isolateReadout(s1, s2 *Sprite) []coloredText {
+	fieldUnit, kind := "N/C", "No force"
+	if theGame.gravity {
+		fieldUnit = "N/kg"
+	}
+	f := force(s1, s2)
+	switch {
+	case f > 0:
+		kind = "Repulsion"
+	case f < 0:
+		kind = "Attraction"
+	}
+	r := distance(s1, s2)
+	forceText, forceColor := formatValue("F = %.2e N", f)
+	field1, field1Color := formatValue("%.2e "+fieldUnit, field(s1.source(), r))
+	field2, field2Color := formatValue("%.2e "+fieldUnit, field(s2.source(), r))
+	return []coloredText{
+		{fmt.Sprintf("%s and %s", s1.name, s2.name), color.White},
+		{fmt.Sprintf("Separation r = %.2f m", r), color.White},
+		{forceText + " (" + kind + ")", forceColor},
+		{fmt.Sprintf("Field of %s at %s: %s", s1.name, s2.name, field1), field1Color},
+		{fmt.Sprintf("Field of %s at %s: %s", s2.name, s1.name, field2), field2Color},
+		{fmt.Sprintf("Direction from %s to %s: %.0f°", s1.name, s2.name, angle(s2, s1)*180/math.Pi), color.White},
+	}
+}
+
+// drawIsolated dims the whole playfield but the isolated pair, and details their interaction in large text,
+// on the half of the playfield away from them
+func drawIsolated(screen *ebiten.Image, g *Game) {
+	if g.isolated == nil {
+		return
+	}
+	s1, s2 := g.isolated[0], g.isolated[1]
+	width, height := g.playfield()
+	drawRectangle(screen, 0, 0, float64(width), float64(height), color.NRGBA{0x00, 0x00, 0x00, 0xc0})
+	drawElectricalInformation(screen, s1, s2)
+	s1.Draw(screen, 0, 0, 1)
+	s2.Draw(screen, 0, 0, 1)
+
+	lines := isolateReadout(s1, s2)
+	lineHeight := int(float64(fontHeight) * isolateFontScale * 1.5)
+	margin := lineHeight / 2
+	boxWidth := 0
+	for _, l := range lines {
+		if w := font.MeasureString(g.largeFont, l.text).Ceil(); w > boxWidth {
+			boxWidth = w
+		}
+	}
+	boxWidth += 2 * margin
+	boxHeight := len(lines)*lineHeight + margin
+	x := (width - boxWidth) / 2
+	y := margin
+	if _, y1 := s1.center(); y1 < float64(height)/2 {
+		if _, y2 := s2.center(); y2 < float64(height)/2 {
+			y = height - boxHeight - margin
+		}
+	}
+	drawRectangle(screen, float64(x), float64(y), float64(boxWidth), float64(boxHeight), color.NRGBA{0x20, 0x20, 0x20, 0xe0})
+	for i, l := range lines {
+		text.Draw(screen, l.text, g.largeFont, x+margin, y+(i+1)*lineHeight, l.color)
+	}
+}
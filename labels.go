@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+
+	"golang.org/x/image/font"
+)
+
+// labelPosition returns where to draw the baseline of the first of lines lines of text, textWidth px wide and
+// lineSpacing px apart, labelling s. They are drawn offset px below the top of s unless they would run off the
+// bottom of the playfield, where they are flipped above it, and moved left when they would run off its right edge,
+// so they stay fully visible next to the sprite.
+func labelPosition(g *Game, s *Sprite, textWidth, lines, offset, lineSpacing int) (int, int) {
+	width, height := g.playfield()
+	_, h := s.image.Size()
+	// the text reaches about fontHeight above its baselines and a quarter of it below
+	descent := fontHeight / 4
+	x, y := s.x, s.y+offset
+	if y+(lines-1)*lineSpacing+descent > height {
+		// the same gap between the sprite and the text as below it
+		gap := offset - fontHeight - h
+		y = s.y - gap - descent - (lines-1)*lineSpacing
+	}
+	if y-fontHeight < 0 {
+		y = fontHeight
+	}
+	if x+textWidth > width {
+		x = width - textWidth
+	}
+	if x < 0 {
+		x = 0
+	}
+	return x, y
+}
+
+// pairLabels returns the force and field values labelling sprite2 in its pair with sprite1, as drawn on screen
+// and exported to SVG, with the baseline of the first one placed by labelPosition and the spacing of the lines
+func pairLabels(g *Game, sprite1, sprite2 *Sprite) (labels []coloredText, x, y, lineSpacing int) {
+	forceLabel, fieldLabel := "F= %.2e N", "E= %.2e N/C"
+	if g.gravity {
+		forceLabel, fieldLabel = "F_grav= %.2e N", "g= %.2e N/kg"
+	}
+	forceText, forceColor := formatValue(forceLabel, force(sprite1, sprite2))
+	fieldText, fieldColor := formatValue(fieldLabel, field(sprite1.source(), distance(sprite1, sprite2)))
+	labels = []coloredText{{forceText, forceColor}, {fieldText, fieldColor}}
+	textWidth := 0.
+	for _, l := range labels {
+		textWidth = math.Max(textWidth, float64(font.MeasureString(g.Font, l.text).Ceil()))
+	}
+	lineSpacing = fontHeight + fontHeight/10
+	x, y = labelPosition(g, sprite2, int(textWidth), len(labels), fontHeight*4, lineSpacing)
+	return labels, x, y, lineSpacing
+}
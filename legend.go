@@ -0,0 +1,77 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+// legendEntry is a color of the legend with its meaning
+type legendEntry struct {
+	color color.Color
+	label string
+}
+
+// legendEntries lists the meaning of the colors used to draw the charges and their interactions
+var legendEntries = []legendEntry{
+	{positiveColor, "Positive charge"},
+	{negativeColor, "Negative charge"},
+	{neutralColor, "Neutral charge"},
+	{fixedColor, "Fixed charge border"},
+	{selectionColor, "Selected charge ring"},
+	{repulsionColor, "Repulsion"},
+	{attractionColor, "Attraction"},
+	{noInteractionColor, "No force"},
+	{forceColor, "Net force"},
+	{weakForceColor, "Weakest net force"},
+	{strongForceColor, "Strongest net force"},
+	{velocityColor, "Velocity"},
+	{nullPointColor, "Null point (no field)"},
+	{probeColor, "Field probe"},
+	{mirrorColor, "Mirrored pair and axis"},
+	{color.NRGBA{0x00, 0x40, 0xff, 0xff}, "Weakest field"},
+	{color.NRGBA{0xff, 0x40, 0x00, 0xff}, "Strongest field"},
+	{color.NRGBA{0xff, 0x90, 0x30, 0xff}, "Positive potential"},
+	{color.NRGBA{0x40, 0x90, 0xff, 0xff}, "Negative potential"},
+	{offScaleColor, "Off scale value"},
+}
+
+// drawRectangle fills a rectangle of the screen with a color
+func drawRectangle(screen *ebiten.Image, x, y, width, height float64, clr color.Color) {
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(width, height)
+	opts.GeoM.Translate(x, y)
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	opts.ColorM.Scale(float64(c.R)/0xff, float64(c.G)/0xff, float64(c.B)/0xff, float64(c.A)/0xff)
+	screen.DrawImage(rectangle, opts)
+}
+
+// drawLegend draws the meaning of each color in a box on the right of the playfield,
+// above the texts drawn at its bottom
+func drawLegend(screen *ebiten.Image, g *Game) {
+	lineHeight := fontHeight + fontHeight/2
+	labelWidth := 0
+	for _, e := range legendEntries {
+		if w := font.MeasureString(g.Font, e.label).Ceil(); w > labelWidth {
+			labelWidth = w
+		}
+	}
+	margin := fontHeight / 2
+	width := margin + fontHeight + margin + labelWidth + margin
+	height := len(legendEntries)*lineHeight + margin
+	_, playfieldHeight := g.playfield()
+	x := g.width - width - fullScreenWidth*.01
+	y := playfieldHeight - 3*fontHeight - height
+	if y < 0 {
+		y = 0
+	}
+
+	drawRectangle(screen, float64(x), float64(y), float64(width), float64(height), color.NRGBA{0x00, 0x00, 0x00, 0xc0})
+	for i, e := range legendEntries {
+		top := y + margin + i*lineHeight
+		drawRectangle(screen, float64(x+margin), float64(top), float64(fontHeight), float64(fontHeight), e.color)
+		text.Draw(screen, e.label, g.Font, x+margin+fontHeight+margin, top+fontHeight, color.White)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
@@ -9,10 +10,14 @@ import (
 	"log"
 	"math"
 	"math/rand"
+	"sort"
 	"strconv"
+	"time"
 
 	"golang.org/x/image/font"
 
+	"github.com/auyer/electrical-charges/charges"
+	"github.com/auyer/electrical-charges/charges/scene"
 	"github.com/auyer/electrical-charges/sprites"
 	"github.com/hajimehoshi/ebiten/text"
 
@@ -24,27 +29,212 @@ import (
 	"github.com/hajimehoshi/ebiten/inpututil"
 )
 
-// distance calculates the distance (Pythagorean Theorem) using the spacial coordinates of the charges
+// The units go from the screen to the physics as follows:
+// sprite positions are in px, and unitScale px on screen (100 by default) are 1 m, so distance returns meters;
+// charges are in coulombs, so with k in Nm²/C² force returns newtons and field returns N/C.
+// Both can be changed with the -unit-scale and -k flags, for example to use Gaussian units,
+// in which case the "m", "N" and "C" shown on screen stand for the units matching the flags.
+
+// particle converts a sprite to the particle used by the physics, in meters.
+// The depth (z) of the sprite is only taken into account in perspective mode.
+// In gravity mode the charge of the particle is the mass of the sprite, see source.
+func (s *Sprite) particle() charges.Particle {
+	p := charges.Particle{X: float64(s.x) / theGame.unitScale, Y: float64(s.y) / theGame.unitScale, Charge: s.source()}
+	if theGame.perspective {
+		p.Z = float64(s.z) / theGame.unitScale
+	}
+	return p
+}
+
+// distance calculates the distance between two charges, in m
 func distance(particle1, particle2 *Sprite) float64 {
-	deltaX := float64(particle1.x - particle2.x)
-	deltaY := float64(particle1.y - particle2.y)
-	return math.Sqrt(deltaX*deltaX+deltaY*deltaY) / 100 // this division turns the distance scale to cm/px instead for m/px
+	return charges.Distance(particle1.particle(), particle2.particle())
+}
+
+// coincident reports if two charges are at the same position, where the direction between them is undefined
+func coincident(particle1, particle2 *Sprite) bool {
+	return charges.Coincident(particle1.particle(), particle2.particle())
 }
 
-// force calculates the force between two charges
+// force calculates the force between two charges, in N.
+// It is positive when the charges repel each other and negative when they attract.
 func force(particle1 *Sprite, particle2 *Sprite) float64 {
-	d := distance(particle1, particle2)
-	return k * (particle1.charge * particle2.charge) / (d * d)
+	return theGame.kScale() * charges.Force(particle1.particle(), particle2.particle(), theGame.exponent)
 }
 
-// field calculates the eletric field on a given radius
+// field calculates the eletric field on a given radius (in m), in N/C
 func field(charge float64, radius float64) float64 {
-	return k * charge / (radius * radius)
+	return theGame.kScale() * charges.Field(charge, radius, theGame.exponent)
+}
+
+// potential calculates the electric potential on a given radius (in m), in V.
+// It is the potential energy per coulomb of the force law, k*q/r for the inverse square law.
+func potential(charge float64, radius float64) float64 {
+	return theGame.kScale() * charges.Potential(charge, radius, theGame.exponent)
+}
+
+// powerText formats an exponent as a superscript where the font supports it
+func powerText(n float64) string {
+	switch n {
+	case 1:
+		return ""
+	case 2:
+		return "²"
+	case 3:
+		return "³"
+	default:
+		return "^" + strconv.FormatFloat(n, 'f', -1, 64)
+	}
 }
 
 // angle calculates the angle in rads by the arc tangent of the tangent formed by the two charges
 func angle(particle1 *Sprite, particle2 *Sprite) float64 {
-	return math.Atan2(float64(particle1.y-particle2.y), float64(particle1.x-particle2.x))
+	return charges.Angle(particle1.particle(), particle2.particle())
+}
+
+// netForce sums the force vectors exerted on target by every other charge, returning its x and y components.
+// In perspective mode only the part of the force along the screen plane is kept.
+// With many charges the far ones are approximated through the spatial grid of the game, see updateGrid.
+func netForce(g *Game, target *Sprite) (fx, fy float64) {
+	if g.grid != nil {
+		fx, fy := g.grid.NetForce(target.particle(), theGame.exponent)
+		return theGame.kScale() * fx, theGame.kScale() * fy
+	}
+	others := []charges.Particle{}
+	for _, other := range g.sprites {
+		if other != target {
+			others = append(others, other.particle())
+		}
+	}
+	fx, fy = charges.NetForce(target.particle(), others, theGame.exponent)
+	return theGame.kScale() * fx, theGame.kScale() * fy
+}
+
+// totalEnergy sums the electrostatic potential energy of every pair of charges, in J.
+// Each pair is counted once, and coincident charges stay finite as distance never goes below charges.MinDistance.
+func totalEnergy(g *Game) float64 {
+	u := 0.
+	for i, s1 := range g.sprites {
+		for _, s2 := range g.sprites[i+1:] {
+			u += s2.source() * potential(s1.source(), distance(s1, s2))
+		}
+	}
+	return u
+}
+
+// totalCharge sums the charges of all the sprites, in C.
+// Sums cancelling out up to the float errors are exactly zero, so a neutral system shows as neutral.
+func totalCharge(g *Game) float64 {
+	q, magnitude := 0., 0.
+	for _, s := range g.sprites {
+		q += s.charge
+		magnitude += math.Abs(s.charge)
+	}
+	if math.Abs(q) <= 1e-9*magnitude {
+		return 0
+	}
+	return q
+}
+
+// showChargeLedger shows the total charge before an operation adding or removing charges and after it,
+// confirming the operations dividing charges conserve it and telling how much the others added or removed
+func (g *Game) showChargeLedger(before float64) {
+	after := totalCharge(g)
+	str := fmt.Sprintf("Total charge %+.2f C → %+.2f C", before, after)
+	if math.Abs(after-before) <= 1e-9*math.Max(math.Abs(before), math.Abs(after)) {
+		str += ", conserved"
+	} else {
+		str += fmt.Sprintf(", changed by %+.2f C", after-before)
+	}
+	g.showNotice(str)
+}
+
+// totalChargeReadout returns the total charge of the system, colored like a charge of its sign
+func totalChargeReadout(g *Game) coloredText {
+	if len(g.sprites) == 0 {
+		return coloredText{}
+	}
+	q := totalCharge(g)
+	sign := "neutral"
+	switch {
+	case q > 0:
+		sign = "positive"
+	case q < 0:
+		sign = "negative"
+	}
+	return coloredText{fmt.Sprintf("Total charge Q = %+.2f C (%s)", q, sign), chargeColor(q)}
+}
+
+// energyReadout returns the potential energy of the system, once there are charges interacting
+func energyReadout(g *Game) coloredText {
+	if len(g.sprites) < 2 {
+		return coloredText{}
+	}
+	energyText, energyColor := formatValue("U = %.2e J", totalEnergy(g))
+	return coloredText{energyText, energyColor}
+}
+
+// dipoleMoment returns the dipole moment p = q*d of the system, in C·m, where d goes from the negative charge
+// to the positive one. ok is false unless there are exactly two charges (not counting the neutral ones),
+// of equal magnitude and opposite signs.
+func dipoleMoment(g *Game) (px, py float64, ok bool) {
+	charged := []*Sprite{}
+	for _, s := range g.sprites {
+		if s.charge != 0 {
+			charged = append(charged, s)
+		}
+	}
+	if len(charged) != 2 {
+		return 0, 0, false
+	}
+	positive, negative := charged[0], charged[1]
+	if positive.charge < 0 {
+		positive, negative = negative, positive
+	}
+	if negative.charge >= 0 || math.Abs(positive.charge+negative.charge) > 1e-9*positive.charge {
+		return 0, 0, false
+	}
+	p := positive.charge * distance(positive, negative)
+	a := angle(positive, negative)
+	return p * math.Cos(a), p * math.Sin(a), true
+}
+
+// dipoleReadout returns the dipole moment of the system when it is a dipole
+func dipoleReadout(g *Game) coloredText {
+	px, py, ok := dipoleMoment(g)
+	if !ok {
+		return coloredText{}
+	}
+	return coloredText{fmt.Sprintf("Dipole p = %.2e C·m at %.0f°", math.Hypot(px, py), math.Atan2(py, px)*180/math.Pi), color.White}
+}
+
+// readouts returns the values describing the whole system that are shown
+func readouts(g *Game) []coloredText {
+	shown := []coloredText{}
+	for _, r := range []coloredText{recoveryReadout(g), gravityReadout(g), netForcesReadout(g), totalChargeReadout(g), energyReadout(g), dipoleReadout(g), duplicateNamesReadout(g)} {
+		if r.text != "" {
+			shown = append(shown, r)
+		}
+	}
+	return shown
+}
+
+// drawReadouts draws the readouts on the top right, one below the other
+func drawReadouts(screen *ebiten.Image, g *Game) {
+	y := fullScreenHeight*.05 + fontHeight*3
+	for _, r := range readouts(g) {
+		drawTextRight(screen, r.text, y, r.color)
+		y += fontHeight + fontHeight/2
+	}
+}
+
+// panelTop returns the top of the panels drawn on the right side, below the readouts
+func panelTop(g *Game) int {
+	top := fullScreenHeight*.05 + fontHeight*9
+	if below := fullScreenHeight*.05 + fontHeight*3 + len(readouts(g))*(fontHeight+fontHeight/2); below > top {
+		return below
+	}
+	return top
 }
 
 func midPoint(particle1, particle2 *Sprite) (int, int) {
@@ -53,30 +243,320 @@ func midPoint(particle1, particle2 *Sprite) (int, int) {
 
 // drawElectricalInformation draws the electrical information generated between two charges
 func drawElectricalInformation(screen *ebiten.Image, sprite1, sprite2 *Sprite) {
+	width := linkWidth(math.Abs(force(sprite1, sprite2)))
+	drawThickLine(screen, float64(sprite1.x)+20, float64(sprite1.y)+20, float64(sprite2.x)+20, float64(sprite2.y)+20, width, overlayColor(interactionColor(sprite1.charge, sprite2.charge)))
+	midx, midy := midPoint(sprite1, sprite2)
+	text.Draw(screen, fmt.Sprintf("%.2f m", distance(sprite1, sprite2)), theGame.Font, midx, midy, overlayColor(color.White))
+	labels, x, y, lineSpacing := pairLabels(theGame, sprite1, sprite2)
+	for i, l := range labels {
+		text.Draw(screen, l.text, theGame.Font, x, y+i*lineSpacing, overlayColor(l.color))
+	}
+	drawPairForce(screen, sprite1, sprite2)
+}
+
+// drawPairForce draws the force exerted by sprite1 on sprite2 as an arrow from the center of sprite2,
+// pointing away from sprite1 when they repel each other and towards it when they attract
+func drawPairForce(screen *ebiten.Image, sprite1, sprite2 *Sprite) {
+	f := force(sprite1, sprite2)
+	if f == 0 || coincident(sprite1, sprite2) {
+		return
+	}
+	a := angle(sprite2, sprite1)
+	if f < 0 {
+		a += math.Pi
+	}
+	x, y := sprite2.center()
+	drawArrow(screen, x, y, a, arrowLength(math.Abs(f)), overlayColor(interactionColor(sprite1.charge, sprite2.charge)))
+}
+
+// drawLine draws a 1px wide line from (x1, y1) to (x2, y2)
+func drawLine(screen *ebiten.Image, x1, y1, x2, y2 float64, clr color.Color) {
 	opt := &ebiten.DrawImageOptions{}
-	opt.GeoM.Scale(1, distance(sprite1, sprite2)*100)
-	opt.GeoM.Rotate(angle(sprite1, sprite2) + math.Pi/2)
-	opt.GeoM.Translate(float64(sprite1.x)+20, float64(sprite1.y)+20)
+	opt.GeoM.Scale(1, math.Hypot(x2-x1, y2-y1))
+	opt.GeoM.Rotate(math.Atan2(y2-y1, x2-x1) - math.Pi/2)
+	opt.GeoM.Translate(x1, y1)
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	opt.ColorM.Scale(float64(c.R)/0xff, float64(c.G)/0xff, float64(c.B)/0xff, float64(c.A)/0xff)
 	screen.DrawImage(line, opt)
-	midx, midy := midPoint(sprite1, sprite2)
-	text.Draw(screen, fmt.Sprintf("%.2f m", distance(sprite1, sprite2)), theGame.Font, midx, midy, color.White)
-	text.Draw(screen, fmt.Sprintf("F= %.2e N", force(sprite1, sprite2)), theGame.Font, sprite2.x, sprite2.y+fontHeight*4, color.White)
-	text.Draw(screen, fmt.Sprintf("E= %.2e N/C", field(sprite1.charge, distance(sprite1, sprite2))), theGame.Font, sprite2.x, sprite2.y+fontHeight/10+fontHeight*5, color.White)
+}
+
+// drawThickLine draws a line from (x1, y1) to (x2, y2) of the given width in px, centered on the segment
+func drawThickLine(screen *ebiten.Image, x1, y1, x2, y2, width float64, clr color.Color) {
+	opt := &ebiten.DrawImageOptions{}
+	opt.GeoM.Scale(width, math.Hypot(x2-x1, y2-y1))
+	opt.GeoM.Translate(-width/2, 0)
+	opt.GeoM.Rotate(math.Atan2(y2-y1, x2-x1) - math.Pi/2)
+	opt.GeoM.Translate(x1, y1)
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	opt.ColorM.Scale(float64(c.R)/0xff, float64(c.G)/0xff, float64(c.B)/0xff, float64(c.A)/0xff)
+	screen.DrawImage(line, opt)
+}
+
+// linkWidth maps a force magnitude to the width in px of the line linking two charges.
+// Like arrowLength it grows with the logarithm of the magnitude from the same reference force,
+// and is clamped so close charges aren't linked by a wide rectangle.
+func linkWidth(magnitude float64) float64 {
+	if magnitude == 0 {
+		return linkMinWidth
+	}
+	reference := theGame.k * 0.1 * 0.1
+	width := linkReferenceWidth + linkWidthPerDecade*math.Log10(magnitude/reference)
+	return math.Max(linkMinWidth, math.Min(width, linkMaxWidth))
+}
+
+// drawArrow draws an arrow starting at (x, y) with the given length, pointing at the direction a (in rads).
+// It returns the position of the arrow tip.
+func drawArrow(screen *ebiten.Image, x, y, a, length float64, clr color.Color) (float64, float64) {
+	tipX, tipY := x+length*math.Cos(a), y+length*math.Sin(a)
+	head := math.Min(arrowHeadLength, length/3)
+	drawLine(screen, x, y, tipX, tipY, clr)
+	drawLine(screen, tipX, tipY, tipX+head*math.Cos(a+math.Pi*5/6), tipY+head*math.Sin(a+math.Pi*5/6), clr)
+	drawLine(screen, tipX, tipY, tipX+head*math.Cos(a-math.Pi*5/6), tipY+head*math.Sin(a-math.Pi*5/6), clr)
+	return tipX, tipY
+}
+
+// arrowLength maps a force magnitude to an arrow length in px.
+// The length grows with the logarithm of the magnitude, so both tiny and huge forces stay visible,
+// and is clamped so arrows never overflow the window.
+// The reference is the force between two 0.1 C charges 1 m apart.
+func arrowLength(magnitude float64) float64 {
+	reference := theGame.k * 0.1 * 0.1
+	length := arrowReferenceLength + arrowLengthPerDecade*math.Log10(magnitude/reference)
+	return math.Max(arrowMinLength, math.Min(length, arrowMaxLength))
+}
+
+// drawNetForce draws the net force on a sprite as an arrow from its center, with its magnitude by the tip
+func drawNetForce(screen *ebiten.Image, g *Game, s *Sprite) {
+	fx, fy := netForce(g, s)
+	magnitude := math.Hypot(fx, fy)
+	if magnitude == 0 {
+		return
+	}
+	x, y := s.center()
+	tipX, tipY := drawArrow(screen, x, y, math.Atan2(fy, fx), arrowLength(magnitude), overlayColor(forceColor))
+	str, clr := formatValue("%.2e N", magnitude)
+	text.Draw(screen, str, theGame.Font, int(tipX), int(tipY), overlayColor(clr))
+}
+
+// overlayColor applies the overlay opacity chosen by the user to a color
+func overlayColor(clr color.Color) color.Color {
+	return fade(clr, theGame.overlayOpacity)
+}
+
+// fade multiplies the alpha of a color by o
+func fade(clr color.Color, o float64) color.Color {
+	r, g, b, a := clr.RGBA()
+	return color.RGBA64{uint16(float64(r) * o), uint16(float64(g) * o), uint16(float64(b) * o), uint16(float64(a) * o)}
+}
+
+// coloredText is a piece of text drawn with its own color
+type coloredText struct {
+	text  string
+	color color.Color
+}
+
+// drawColoredText draws the text pieces one after the other starting at (x, y)
+func drawColoredText(screen *ebiten.Image, parts []coloredText, x, y int) {
+	for _, p := range parts {
+		text.Draw(screen, p.text, theGame.Font, x, y, p.color)
+		x += font.MeasureString(theGame.Font, p.text).Ceil()
+	}
+}
+
+// imageFor returns the sprite image representing a charge
+func imageFor(charge float64) *ebiten.Image {
+	switch {
+	case charge > 0.:
+		return positiveImage
+	case charge < 0.:
+		return negativeImage
+	default:
+		return neutralImage
+	}
+}
+
+// interactionColor returns the color of the line linking two charges:
+// red when they repel each other, blue when they attract and gray when either is neutral
+func interactionColor(q1, q2 float64) color.Color {
+	switch {
+	case q1*q2 != 0 && theGame.gravity:
+		// masses always attract
+		return attractionColor
+	case q1*q2 > 0:
+		return repulsionColor
+	case q1*q2 < 0:
+		return attractionColor
+	default:
+		return noInteractionColor
+	}
+}
+
+// chargeColor returns the color of the sprite image used for a charge
+func chargeColor(charge float64) color.Color {
+	switch {
+	case charge > 0.:
+		return positiveColor
+	case charge < 0.:
+		return negativeColor
+	default:
+		return neutralColor
+	}
+}
+
+// drawEquation draws Coulomb's law for a pair of charges, symbolic and with the values substituted.
+// Each symbol is colored like its on-screen element: the charges like their sprites and r like the linking line.
+func drawEquation(screen *ebiten.Image, sprite1, sprite2 *Sprite, x, y int) {
+	q1, q2, r := chargeColor(sprite1.charge), chargeColor(sprite2.charge), interactionColor(sprite1.charge, sprite2.charge)
+	k, s1, s2 := "k", "q1", "q2"
+	if theGame.gravity {
+		k, s1, s2 = "G", "m1", "m2"
+	}
+	drawColoredText(screen, []coloredText{
+		{"F = " + k + "·", color.White}, {s1, q1}, {"·", color.White}, {s2, q2}, {" / ", color.White}, {"r", r}, {powerText(theGame.exponent), color.White},
+	}, x, y)
+	forceText, forceColor := formatValue("%.2e N", force(sprite1, sprite2))
+	drawColoredText(screen, []coloredText{
+		{fmt.Sprintf("F = %.2e·", theGame.k), color.White},
+		{fmt.Sprintf("%.2f", sprite1.source()), q1},
+		{"·", color.White},
+		{fmt.Sprintf("%.2f", sprite2.source()), q2},
+		{" / ", color.White},
+		{fmt.Sprintf("%.2f", distance(sprite1, sprite2)), r},
+		{powerText(theGame.exponent) + " = ", color.White},
+		{forceText, forceColor},
+	}, x, y+fontHeight+fontHeight/2)
+}
+
+// offScale reports if a force or field value is too large to be meaningful on screen
+func offScale(value float64) bool {
+	return math.IsNaN(value) || math.Abs(value) >= maxDisplayValue
+}
+
+// formatValue formats a force or field value and returns the color it should be drawn with.
+// Values that are off scale are flagged in red so unphysical setups stand out.
+func formatValue(format string, value float64) (string, color.Color) {
+	str := fmt.Sprintf(format, value)
+	if offScale(value) {
+		return str + " (off scale)", offScaleColor
+	}
+	return str, color.White
 }
 
 var (
 	negativeImage, neutralImage, positiveImage *ebiten.Image
 	rectangle, line                            *ebiten.Image
 	theGame                                    *Game
+	regularFont                                *truetype.Font
 	fontHeight                                 int
+	offScaleColor                              = color.NRGBA{0xff, 0x40, 0x40, 0xff}
+	lineColor                                  = color.NRGBA{0x00, 0xff, 0x00, 0xff}
+	forceColor                                 = color.NRGBA{0xff, 0xa5, 0x00, 0xff}
+	velocityColor                              = color.NRGBA{0x40, 0xe0, 0xff, 0xff}
+	fixedColor                                 = color.NRGBA{0xff, 0xff, 0xff, 0xff}
+	nullPointColor                             = color.NRGBA{0xff, 0xff, 0x80, 0xff}
+	selectionColor                             = color.NRGBA{0xb0, 0xff, 0x20, 0xff}
+
+	// colors of the line linking two charges
+	repulsionColor     = color.NRGBA{0xff, 0x50, 0x50, 0xff}
+	attractionColor    = color.NRGBA{0x50, 0x80, 0xff, 0xff}
+	noInteractionColor = color.NRGBA{0x80, 0x80, 0x80, 0xff}
+
+	// colors matching the sprite images
+	positiveColor = color.NRGBA{0xe8, 0x37, 0x53, 0xff}
+	negativeColor = color.NRGBA{0x62, 0xc3, 0xaa, 0xff}
+	neutralColor  = color.NRGBA{0xff, 0xda, 0x5a, 0xff}
+)
+
+const (
+	defaultUnitScale   = scene.DefaultUnitScale // px on screen for each m
+	defaultFontSize    = 12                     // points, at fontDPI
+	fontDPI            = 142
+	fontSizeStep       = 0.5 // points added or removed by each Ctrl+'+'/Ctrl+'-' press
+	minFontSize        = 6
+	maxFontSize        = 24
+	defaultChargeStep  = 0.1 // C added or removed by each 'P'/'N' press, changed by powers of 10 with '['/']'
+	minChargeStep      = 0.001
+	maxChargeStep      = 10
+	coarseChargeSteps  = 10  // Shift+'P'/'N' step by this many charge steps at once
+	fineMoveStep       = 1   // px moved by Shift+arrows
+	defaultMaxCharge   = 100 // C, the largest magnitude given to a charge while editing, changed with -max-charge
+	fullScreenWidth    = 800
+	fullScreenHeight   = 600
+	maxDisplayValue    = 1e15 // forces (N) and fields (N/C) above this are flagged as off scale
+	opacityStep        = 0.1  // overlay opacity change for each 'O' press
+	focalLength        = 500  // distance in px from the viewer to the screen plane in perspective mode
+	minChargeScale     = 0.6  // smallest scale of the sprites drawn by charge, for the neutral ones
+	maxChargeScale     = 2.5
+	maxDepth           = 250  // maximum distance in px of a charge from the screen plane
+	depthStep          = 50   // depth change for each PageUp/PageDown press
+	minCreationDrag    = 10   // drags in px shorter than this from an empty spot don't create charges
+	chargePerDragPixel = 0.01 // C created for each px dragged from an empty spot
+	exponentStep       = 0.5  // force law exponent change for each '9'/'0' press
+	minExponent        = 1
+	maxExponent        = 4
+
+	// force arrows, see arrowLength
+	arrowReferenceLength = 60
+	arrowLengthPerDecade = 15
+	arrowMinLength       = 10
+	arrowMaxLength       = 200
+	arrowHeadLength      = 10
+
+	// lines linking two charges, see linkWidth
+	linkReferenceWidth = 2
+	linkWidthPerDecade = 1
+	linkMinWidth       = 1
+	linkMaxWidth       = 6
+
+	maxSpeed    = 10 // m/s, keeps the motion stable when charges get too close
+	defaultMass = 1  // kg, mass of the new charges
+
+	maxStepDt     = 1. / 60 // s, longest integration step, the ticks of sped up motion being split in several steps
+	timeScaleStep = 2       // time scale change for each '<'/'>' press
+	minTimeScale  = 1. / 8
+	maxTimeScale  = 16
+
+	velocityArrowScale = 20  // px of velocity arrow for each m/s
+	defaultTrailLength = 60  // positions kept in the trail of each charge
+	trailClearTicks    = 120 // ticks after pausing the motion before the trails are cleared
+
+	fieldGridSpacing     = 40 // px between the field arrows
+	equipotentialSpacing = 10 // px between the potential samples used for the equipotential lines
+	fieldLinesPerCoulomb = 80 // field lines starting from a charge for each C it carries
+	maxFieldLines        = 32 // field lines starting from a charge, whatever its magnitude
+	fieldLineStep        = 5  // px advanced along the field for each segment of a field line
+	fieldLineMaxSteps    = 400
+	heatmapCellSize      = 4   // px between the samples of the field heatmap
+	heatmapOpacity       = 0.6 // the heatmap is drawn faded, so the charges and texts stay readable
+
+	spatialCellSize      = 0.5 // m, side of the cells of the spatial grid
+	spatialCutoff        = 1   // m, charges closer than this are always summed exactly
+	exactForcesThreshold = 200 // forces and fields are exact up to this many charges
+
+	buttonWidth      = 80 // px
+	buttonSpacing    = 8  // px between the buttons, and from the edge of the screen
+	buttonPressDepth = 2  // px the buttons move down while pressed
+
+	maxOverlapPasses = 4 // times pushApart goes over the sprites to separate a dragged one
+
+	defaultGridSize = 50 // px between the grid lines the charges snap to
+	minGridSize     = 10
+	maxGridSize     = 200
+	gridSizeStep    = 10
+
+	pasteOffset = 20 // px between a copied charge and its pasted copy, along both axes
+
+	selectionRingGap   = 4 // px between a selected sprite and the ring around it
+	selectionRingWidth = 2 // px
+	ringSegments       = 32
 )
 
+// keys held down repeat after keyRepeatDelay ticks, see keyRepeatSteps
 const (
-	k                = 0.000000009 // Nm²/C²
-	fullScreenWidth  = 800
-	fullScreenHeight = 600
-	screenWidth      = fullScreenWidth
-	screenHeight     = fullScreenHeight * .9
+	keyRepeatDelay      = 30
+	keyRepeatInterval   = 4
+	keyRepeatAccelTicks = 60
+	keyRepeatMaxSteps   = 16
 )
 
 // Sprite represents an image.
@@ -85,8 +565,20 @@ type Sprite struct {
 	image  *ebiten.Image
 	x      int
 	y      int
+	z      int // depth, only used in perspective mode
 	charge float64
-	chosen bool
+	mass   float64 // in kg, always positive
+	fixed  bool    // fixed charges don't move with the motion, but still exert forces
+	// mirror is the other sprite of the mirrored pair of the sprite, if any, see toggleMirror
+	mirror *Sprite
+
+	// vx and vy are the velocity in m/s, used when the motion is playing
+	vx, vy float64
+	// remainderX and remainderY hold the fraction of pixel moved but not yet applied to x and y
+	remainderX, remainderY float64
+	// trail is a ring buffer of the last centers of the sprite while moving, trailLen of them being used from trailStart
+	trail                [][2]float64
+	trailStart, trailLen int
 }
 
 // In returns true if (x, y) is in the sprite, and false otherwise.
@@ -97,49 +589,155 @@ func (s *Sprite) In(x, y int) bool {
 	// Note that this is not a good manner to use At for logic
 	// since color from At might include some errors on some machines.
 	// As this is not so important logic, it's ok to use it so far.
-	return s.image.At(x-s.x, y-s.y).(color.RGBA).A > 0
+	//
+	// The sprite is scaled around its center, so the point is scaled back before checking.
+	// Its rotation is ignored, as the round images cover the same pixels whatever their rotation.
+	w, h := s.image.Size()
+	scale := s.scale()
+	ix := int(float64(x-s.x-w/2)/scale) + w/2
+	iy := int(float64(y-s.y-h/2)/scale) + h/2
+	return s.image.At(ix, iy).(color.RGBA).A > 0
+}
+
+// center returns the position of the center of the sprite
+func (s *Sprite) center() (float64, float64) {
+	w, h := s.image.Size()
+	return float64(s.x) + float64(w)/2, float64(s.y) + float64(h)/2
+}
+
+// radius returns the radius of the sprite as drawn on screen, in px
+func (s *Sprite) radius() float64 {
+	w, _ := s.image.Size()
+	return float64(w) / 2 * s.scale()
+}
+
+// scale returns how much the sprite is scaled when drawn, by its depth and by its charge if sizeByCharge is on
+func (s *Sprite) scale() float64 {
+	return s.depthScale() * s.chargeScale()
+}
+
+// chargeScale returns how much the sprite is scaled by its charge when sizeByCharge is on.
+// The area of the sprite is proportional to the magnitude of the charge, a charge of one default step
+// keeping the size of the image, between minChargeScale and maxChargeScale.
+func (s *Sprite) chargeScale() float64 {
+	if !theGame.sizeByCharge {
+		return 1
+	}
+	scale := math.Sqrt(math.Abs(s.charge) / defaultChargeStep)
+	return math.Max(minChargeScale, math.Min(scale, maxChargeScale))
+}
+
+// depthScale returns how much the sprite is scaled by its depth in perspective mode.
+// Charges further away (positive z) are drawn smaller.
+func (s *Sprite) depthScale() float64 {
+	if !theGame.perspective {
+		return 1
+	}
+	return focalLength / (focalLength + float64(s.z))
+}
+
+// MoveDepthBy moves the sprite away from the viewer by z, keeping it between -maxDepth and maxDepth.
+func (s *Sprite) MoveDepthBy(z int) {
+	s.z += z
+	if s.z < -maxDepth {
+		s.z = -maxDepth
+	}
+	if s.z > maxDepth {
+		s.z = maxDepth
+	}
 }
 
-// MoveBy moves the sprite by (x, y).
+// MoveBy moves the sprite by (x, y), keeping it inside the playfield as drawn, scaled around its center.
+// A sprite larger than the playfield is kept at its top left corner.
 func (s *Sprite) MoveBy(x, y int) {
 	w, h := s.image.Size()
+	width, height := theGame.playfield()
+	// how far the scaled sprite goes past its image on each side, negative when it is drawn smaller,
+	// rounded up so a fraction of pixel never goes past the edges
+	mx := int(math.Ceil(float64(w) * (s.scale() - 1) / 2))
+	my := int(math.Ceil(float64(h) * (s.scale() - 1) / 2))
 
 	s.x += x
 	s.y += y
-	if s.x < 0 {
-		s.x = 0
+	// the far edges are clamped first, so the near ones win when the sprite doesn't fit
+	if s.x > width-w-mx {
+		s.x = width - w - mx
 	}
-	if s.x > screenWidth-w {
-		s.x = screenWidth - w
+	if s.x < mx {
+		s.x = mx
 	}
-	if s.y < 0 {
-		s.y = 0
+	if s.y > height-h-my {
+		s.y = height - h - my
 	}
-	if s.y > screenHeight-h {
-		s.y = screenHeight - h
+	if s.y < my {
+		s.y = my
 	}
 }
 
 // Draw draws the sprite.
 func (s *Sprite) Draw(screen *ebiten.Image, dx, dy int, alpha float64) {
 	op := &ebiten.DrawImageOptions{}
-	// op.GeoM.Scale(0.5, 0.5)
-	op.GeoM.Translate(float64(s.x+dx), float64(s.y+dy))
-	if s.chosen {
-		op.ColorM.Scale(0.5, 0.5, 0.5, alpha)
-	} else {
-		op.ColorM.Scale(1, 1, 1, alpha)
+	w, h := s.image.Size()
+	scale := s.scale()
+	op.GeoM.Translate(-float64(w)/2, -float64(h)/2)
+	op.GeoM.Rotate(s.rotation())
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(float64(s.x+dx+w/2), float64(s.y+dy+h/2))
+	op.ColorM.Scale(1, 1, 1, alpha)
+	if theGame.showNames {
+		// the names of the charges further away fade
+		text.Draw(screen, s.name, theGame.Font, s.x, s.y, fade(color.White, math.Min(s.depthScale(), 1)))
 	}
-	text.Draw(screen, s.name, theGame.Font, s.x, s.y, color.White)
 	screen.DrawImage(s.image, op)
+	if s.fixed {
+		// a square around the sprite marks it as pinned
+		cx, cy := float64(s.x+dx+w/2), float64(s.y+dy+h/2)
+		r := s.radius()
+		clr := fade(fixedColor, alpha)
+		drawLine(screen, cx-r, cy-r, cx+r, cy-r, clr)
+		drawLine(screen, cx+r, cy-r, cx+r, cy+r, clr)
+		drawLine(screen, cx+r, cy+r, cx-r, cy+r, clr)
+		drawLine(screen, cx-r, cy+r, cx-r, cy-r, clr)
+	}
+	if theGame.isSelected(s) {
+		// a ring around the sprite, which stays visible whatever the color of its image
+		cx, cy := float64(s.x+dx+w/2), float64(s.y+dy+h/2)
+		drawRing(screen, cx, cy, s.radius()+selectionRingGap, selectionRingWidth, fade(selectionColor, alpha))
+	}
+}
 
+// drawRing draws a circle of the given radius and line width centered on (cx, cy), as a polygon of ringSegments sides
+func drawRing(screen *ebiten.Image, cx, cy, radius, width float64, clr color.Color) {
+	for i := 0; i < ringSegments; i++ {
+		a1 := 2 * math.Pi * float64(i) / ringSegments
+		a2 := 2 * math.Pi * float64(i+1) / ringSegments
+		drawThickLine(screen, cx+radius*math.Cos(a1), cy+radius*math.Sin(a1), cx+radius*math.Cos(a2), cy+radius*math.Sin(a2), width, clr)
+	}
 }
 
 // DrawStatistics draws the sprites charge on the top of the screen.
 func (s *Sprite) DrawStatistics(screen *ebiten.Image, x, y int, alpha float64) {
-	text.Draw(screen, fmt.Sprintf("'E' = Electric Field generated by %s.                        Negative = repulsion", s.name), theGame.Font, x, y, color.White)
-	text.Draw(screen, fmt.Sprintf("'F' = Force between %s and each charge.                 Positive  = attraction", s.name), theGame.Font, x, y+fontHeight+fontHeight/2, color.White)
-	text.Draw(screen, fmt.Sprintf("%s Charge : %.2f C.", s.name, s.charge), theGame.Font, x, screenHeight, color.White)
+	_, height := theGame.playfield()
+	lock := ""
+	if theGame.signLock {
+		lock = " Sign locked."
+	}
+	if theGame.gravity {
+		text.Draw(screen, fmt.Sprintf("'g' = Gravitational Field generated by %s.", s.name), theGame.Font, x, y, color.White)
+		text.Draw(screen, fmt.Sprintf("'F_grav' = Gravitation between %s and each mass, always attracting.", s.name), theGame.Font, x, y+fontHeight+fontHeight/2, color.White)
+	} else {
+		text.Draw(screen, fmt.Sprintf("'E' = Electric Field generated by %s.                        Negative = repulsion", s.name), theGame.Font, x, y, color.White)
+		text.Draw(screen, fmt.Sprintf("'F' = Force between %s and each charge.                 Positive  = attraction", s.name), theGame.Font, x, y+fontHeight+fontHeight/2, color.White)
+	}
+	// in gravity mode the charge is the mass pulling the others, and the mass the inertia of the sprite
+	str := fmt.Sprintf("%s Charge : %.2f C.%s   Mass : %.2f kg.", s.name, s.charge, lock, s.mass)
+	if theGame.gravity {
+		str = fmt.Sprintf("%s Mass : %.2f kg.%s   Inertia : %.2f kg.", s.name, s.source(), lock, s.mass)
+	}
+	if theGame.perspective {
+		str += fmt.Sprintf("   Depth : %.2f m.", float64(s.z)/theGame.unitScale)
+	}
+	text.Draw(screen, str, theGame.Font, x, height, color.White)
 }
 
 // StrokeSource represents a input device to provide strokes.
@@ -188,6 +786,10 @@ type Stroke struct {
 	currentX int
 	currentY int
 
+	// smoothX and smoothY follow the current position, see SmoothPositionDiff
+	smoothX float64
+	smoothY float64
+
 	released bool
 
 	// draggingObject represents a object (sprite in this case)
@@ -204,6 +806,8 @@ func NewStroke(source StrokeSource) *Stroke {
 		initY:    cy,
 		currentX: cx,
 		currentY: cy,
+		smoothX:  float64(cx),
+		smoothY:  float64(cy),
 	}
 }
 
@@ -219,6 +823,9 @@ func (s *Stroke) Update() {
 	x, y := s.source.Position()
 	s.currentX = x
 	s.currentY = y
+	// exponential smoothing, going a dragSmoothing fraction of the way to the current position each tick
+	s.smoothX += (float64(x) - s.smoothX) * theGame.dragSmoothing
+	s.smoothY += (float64(y) - s.smoothY) * theGame.dragSmoothing
 }
 
 // IsReleased checks if the stroke was releases
@@ -238,6 +845,14 @@ func (s *Stroke) PositionDiff() (int, int) {
 	return dx, dy
 }
 
+// SmoothPositionDiff is like PositionDiff, but following the position smoothed over the last ticks.
+// It is only meant for drawing, the objects being dropped at the exact position.
+func (s *Stroke) SmoothPositionDiff() (int, int) {
+	dx := int(math.Round(s.smoothX)) - s.initX
+	dy := int(math.Round(s.smoothY)) - s.initY
+	return dx, dy
+}
+
 // DraggingObject returns the object being dragged
 func (s *Stroke) DraggingObject() interface{} {
 	return s.draggingObject
@@ -248,24 +863,198 @@ func (s *Stroke) SetDraggingObject(object interface{}) {
 	s.draggingObject = object
 }
 
+// ChargeCreation is the object dragged when pressing on an empty spot.
+// Dragging away from the spot creates a new charge there, with a magnitude proportional to the drag distance.
+type ChargeCreation struct{}
+
+// Charge returns the charge created by a drag of (dx, dy), or 0 if the drag is too short to be intentional.
+// Holding Shift makes the charge negative.
+func (c *ChargeCreation) Charge(dx, dy int) float64 {
+	d := math.Hypot(float64(dx), float64(dy))
+	if d < minCreationDrag {
+		return 0
+	}
+	q := math.Round(d*chargePerDragPixel*100) / 100
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		return -q
+	}
+	return q
+}
+
+// draggingObjectAt returns the object to be dragged by a stroke starting over sprite,
+// a ChargeCreation when there is no sprite under it
+func draggingObjectAt(sprite *Sprite) interface{} {
+	if sprite == nil {
+		return &ChargeCreation{}
+	}
+	return sprite
+}
+
 // Game struct stores the game state, its sprites, strokes, Font and the selected sprite
 type Game struct {
-	strokes      map[*Stroke]struct{}
+	// strokes are in the order they started, so they are updated and drawn the same way every frame
+	strokes      []*Stroke
 	sprites      []*Sprite
 	Font         font.Face
+	fontSize     float64 // in points, see setFontSize
+	largeFont    font.Face
 	ChosenSprite *Sprite
+	// selected holds all the selected sprites, ChosenSprite being the last one clicked
+	selected map[*Sprite]struct{}
+
+	// inputMode and inputBuffer hold the state of the text being typed by the user
+	inputMode   inputMode
+	inputBuffer string
+
+	// showNames controls whether the charge names are drawn next to the sprites
+	showNames bool
+
+	// overlayOpacity is the alpha applied to everything drawn on top of the charges (lines, labels)
+	overlayOpacity float64
+
+	// recorder saves the drawn frames while recording, it is nil otherwise
+	recorder *Recorder
+	// storage keeps the scenes, screenshots, exports and recordings, in files or in the browser
+	storage Storage
+
+	// perspective enables the depth of the charges, drawn by scaling the sprites
+	perspective bool
+
+	// exponent is the power of the distance in the force law, 2 for Coulomb's inverse square law
+	exponent float64
+
+	// width and height are the size of the screen, following the window
+	width, height int
+
+	// playing enables the motion of the charges under their forces
+	playing bool
+
+	// showFieldGrid enables drawing the electric field as a grid of arrows
+	showFieldGrid bool
+	// showEquipotentials enables drawing the equipotential lines
+	showEquipotentials bool
+	// showHeatmap enables drawing the field magnitude as a heatmap behind the charges, cached in heatmap
+	showHeatmap bool
+	heatmap     *fieldHeatmap
+	// showFieldLines enables drawing the field lines starting from the charges
+	showFieldLines bool
+	// showForceArrows enables drawing the direction of the net force on every charge that can move
+	showForceArrows bool
+	// rotateSprites turns the image of every charge along the net force on it, see Sprite.rotation
+	rotateSprites bool
+	// netForcesOnly hides the lines and values between the chosen charge and the others,
+	// drawing the net force arrows of all the charges instead
+	netForcesOnly bool
+	// signLock keeps 'P'/'N' from changing the sign of a charge, see stepCharge
+	signLock bool
+	// chargeStep is the charge in C added or removed by each 'P'/'N' press
+	chargeStep float64
+	// rand places the new charges, from seed so runs can be reproduced
+	seed int64
+	rand *rand.Rand
+
+	// k is Coulomb's constant, in Nm²/C², and unitScale the px on screen for each m
+	k, unitScale float64
+
+	// grid is the spatial hash of the charges, rebuilt every tick to speed up the forces and fields with many charges
+	grid *charges.Grid
+	// showLegend enables drawing the meaning of the colors
+	showLegend bool
+	// showTrails enables drawing the trails left by the moving charges, of up to trailLength positions
+	showTrails  bool
+	trailLength int
+	// pausedTicks counts the ticks since the motion was paused, to clear the trails after a while
+	pausedTicks int
+	// energy holds the potential energy of the system over the last seconds of motion, plotted while playing
+	energy energyHistory
+
+	// buttons are the on-screen buttons doing what the keyboard does, for touchscreens
+	buttons []*button
+
+	// preventOverlap pushes the dragged charges out of the other ones
+	preventOverlap bool
+	// dragSmoothing is the fraction of the way to the cursor the dragged sprites are drawn moving each tick,
+	// 1 drawing them right under it
+	dragSmoothing float64
+	// keepDragVelocity lets the dragged charges keep the velocity they had when picked up, instead of
+	// starting again from rest when dropped
+	keepDragVelocity bool
+	// snapToGrid makes the moved charges snap to a grid of gridSize px
+	snapToGrid bool
+	gridSize   int
+
+	// sizeByCharge draws the sprites with an area proportional to their charge, see chargeScale
+	sizeByCharge bool
+
+	// showDiagnostics enables the overlay with the FPS and the number of charges, to spot slowdowns
+	showDiagnostics bool
+
+	// maxCharge is the largest magnitude in C the charges can be given while editing, see capCharge
+	maxCharge float64
+	// notice is a short message shown for noticeTicks, see showNotice
+	notice      string
+	noticeTicks int
+
+	// isolated is the pair of sprites detailed alone on the playfield, if any, see toggleIsolate
+	isolated []*Sprite
+
+	// gravity reads the charges as masses, all attracting each other, see source
+	gravity bool
+
+	// showInspector shows the table of all the charges in place of the breakdown panel,
+	// inspectorScroll being the first charge listed
+	showInspector   bool
+	inspectorScroll int
+
+	// relaxing moves the free charges towards an equilibrium, see relax.
+	// relaxSteps counts the steps done, and relaxInitialForce is the strongest net force before the first one.
+	relaxing          bool
+	relaxSteps        int
+	relaxInitialForce float64
+
+	// timeScale is how many seconds of motion are simulated for each second shown
+	timeScale float64
+
+	// boundary is what happens to the moving charges reaching the edges of the playfield
+	boundary BoundaryMode
+
+	// menu is the context menu opened by right-clicking a sprite, nil when closed
+	menu *contextMenu
+
+	// probeMode makes the clicks on empty spots place probes instead of creating charges, or remove them
+	probeMode bool
+	// contourLevel is the potential in V of the equipotential drawn in probe mode, snapped at the cursor
+	// with Shift+V, or nil when there is none
+	contourLevel *float64
+	probes       []*probe
+
+	// breakdownSprite is the sprite whose forces are listed by the breakdown panel,
+	// which has been scrolling for breakdownTicks
+	breakdownSprite *Sprite
+	breakdownTicks  int
+
+	// undo holds the scenes recorded before the undoable actions, the last one being restored by Ctrl+Z
+	undo []SceneFile
+
+	// autosave is how often the scene is saved to the recovery file, 0 when off, see updateAutosave.
+	// recoveryOffered is set while the recovery file of the previous session waits to be loaded or discarded.
+	autosave        time.Duration
+	autosaveTicks   int
+	recoveryOffered bool
+
+	// clipboard holds the charge copied with Ctrl+C, nil until one is copied.
+	// Its position is where the next paste goes, moving by pasteOffset with each paste.
+	clipboard *SceneCharge
 }
 
 func init() {
-	rand.Seed(25) // Deterministic rand seed
-
-	// creating a white rectangle to be used in the bottom of the screen
-	rectangle, _ = ebiten.NewImage(screenWidth, screenHeight/10, ebiten.FilterNearest)
+	// creating a white rectangle to be used in the bottom of the screen, scaled to its size when drawn
+	rectangle, _ = ebiten.NewImage(1, 1, ebiten.FilterNearest)
 	rectangle.Fill(color.White)
 
-	// creating the line to link particles
+	// creating the line to link particles, colored when drawn
 	line, _ = ebiten.NewImage(1, 1, ebiten.FilterNearest)
-	line.Fill(color.NRGBA{0x00, 0xff, 0x00, 0xff})
+	line.Fill(color.White)
 
 	// negative sprite image
 	negimg, _, err := image.Decode(bytes.NewReader(sprites.Negative))
@@ -288,47 +1077,102 @@ func init() {
 	}
 	positiveImage, _ = ebiten.NewImageFromImage(posimg, ebiten.FilterDefault)
 
-	// creating the font
-	tt, err := truetype.Parse(goregular.TTF)
+	// parsing the font, its faces are created by setFontSize
+	regularFont, err = truetype.Parse(goregular.TTF)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Initialize the sprites.
-	sprites := []*Sprite{}
+	// Initialize the game.
+	theGame = &Game{
+		showNames:      true,
+		overlayOpacity: 1,
+		exponent:       2,
+		k:              charges.K,
+		unitScale:      defaultUnitScale,
+		trailLength:    defaultTrailLength,
+		seed:           time.Now().UnixNano(),
+		buttons:        newButtons(),
+		storage:        newStorage(),
+		gridSize:       defaultGridSize,
+		chargeStep:     defaultChargeStep,
+		boundary:       BoundaryBounce,
+		timeScale:      1,
+		maxCharge:      defaultMaxCharge,
+		dragSmoothing:  1,
+		width:          fullScreenWidth,
+		height:         fullScreenHeight,
+	}
+	theGame.setFontSize(defaultFontSize)
+	theGame.Reset()
+}
+
+// setFontSize replaces the font by a face of the given size in points, kept between minFontSize and maxFontSize,
+// and measures its height again
+func (g *Game) setFontSize(size float64) {
+	g.fontSize = math.Max(minFontSize, math.Min(size, maxFontSize))
+	g.Font = truetype.NewFace(regularFont, &truetype.Options{
+		Size:    g.fontSize,
+		DPI:     fontDPI,
+		Hinting: font.HintingFull,
+	})
+	g.largeFont = truetype.NewFace(regularFont, &truetype.Options{
+		Size:    g.fontSize * isolateFontScale,
+		DPI:     fontDPI,
+		Hinting: font.HintingFull,
+	})
+	b, _, _ := g.Font.GlyphBounds('M')
+	fontHeight = (b.Max.Y - b.Min.Y).Ceil()
+}
+
+// Reset replaces all the charges by the two neutral charges the game starts with,
+// placed at the same positions for the same seed, and cancels the selection and any stroke.
+// The random source is reseeded, so the charges added afterwards are placed the same way too.
+func (g *Game) Reset() {
+	g.rand = rand.New(rand.NewSource(g.seed))
 	w, h := neutralImage.Size()
+	g.sprites = []*Sprite{}
 	for i := 0; i < 2; i++ {
-		s := &Sprite{
-			name:   "Q" + strconv.Itoa(i),
-			image:  neutralImage,
-			x:      rand.Intn(screenWidth - w),
-			y:      rand.Intn(screenHeight - h),
-			charge: 0,
-		}
-		sprites = append(sprites, s)
+		x, y := g.freePosition(w, h)
+		g.addSprite("Q"+strconv.Itoa(i), x, y, 0)
 	}
+	g.strokes = nil
+	g.menu = nil
+	g.probes = nil
+	g.energy.clear()
+	g.isolated = nil
+	g.selectOnly(nil)
+	g.stopInput()
+}
 
-	// Initialize the game.
-	theGame = &Game{
-		strokes: map[*Stroke]struct{}{},
-		sprites: sprites,
-		Font: truetype.NewFace(tt, &truetype.Options{
-			Size:    12,
-			DPI:     142,
-			Hinting: font.HintingFull,
-		}),
-		ChosenSprite: nil,
-	}
-	b, _, _ := theGame.Font.GlyphBounds('M')
-	fontHeight = (b.Max.Y - b.Min.Y).Ceil()
+// playfield returns the size of the area where the charges can be, above the help
+func (g *Game) playfield() (int, int) {
+	return g.width, g.height * 9 / 10
+}
+
+// resize changes the size of the game, keeping the charges at the same relative position in the playfield
+func (g *Game) resize(width, height int) {
+	oldWidth, oldHeight := g.playfield()
+	g.width, g.height = width, height
+	newWidth, newHeight := g.playfield()
+	if oldWidth == 0 || oldHeight == 0 || newWidth == 0 || newHeight == 0 {
+		// nothing to keep proportional to while the window is minimized
+		return
+	}
+	for _, s := range g.sprites {
+		s.x = s.x * newWidth / oldWidth
+		s.y = s.y * newHeight / oldHeight
+		s.MoveBy(0, 0)
+	}
 }
 
 // spriteAt function returns a sprite at the requested function or nil if none is found
 func (g *Game) spriteAt(x, y int) *Sprite {
 	// As the sprites are ordered from back to front,
 	// search the clicked/touched sprite in reverse order.
-	for i := len(g.sprites) - 1; i >= 0; i-- {
-		s := g.sprites[i]
+	sprites := g.drawOrder()
+	for i := len(sprites) - 1; i >= 0; i-- {
+		s := sprites[i]
 		if s.In(x, y) {
 			return s
 		}
@@ -336,178 +1180,1154 @@ func (g *Game) spriteAt(x, y int) *Sprite {
 	return nil
 }
 
-// drawHelp draws the help text on the bottom of the screen
-func drawHelp(screen *ebiten.Image) {
-	textHeight := int(fullScreenHeight - fullScreenHeight*.05)
-	opts := &ebiten.DrawImageOptions{}
-	opts.GeoM.Translate(0, fullScreenHeight*.9+fullScreenHeight*.01)
-	screen.DrawImage(rectangle, opts)
-	text.Draw(screen, "LMB to select charge, drag to move, 'A' to add a new charge, ", theGame.Font, 0, textHeight, color.NRGBA{0xff, 0x00, 0x00, 0xff})
-	text.Draw(screen, "'P' to increase charge, 'N' to decrease charge. ", theGame.Font, 0, textHeight+fontHeight+fontHeight/5, color.NRGBA{0xff, 0x00, 0x00, 0xff})
+// addSprite creates a new neutral looking sprite with the given name, position and charge, adding it to the game
+func (g *Game) addSprite(name string, x, y int, charge float64) *Sprite {
+	s := &Sprite{
+		name:   name,
+		image:  neutralImage,
+		x:      x,
+		y:      y,
+		charge: charge,
+		mass:   defaultMass,
+	}
+	g.sprites = append(g.sprites, s)
+	return s
 }
 
-func (g *Game) updateStroke(stroke *Stroke) {
-	stroke.Update()
-	if !stroke.IsReleased() {
+// addRandomCharge adds a neutral charge at a random position fully inside the playfield,
+// away from the other charges while there is room for it
+func (g *Game) addRandomCharge() {
+	w, h := neutralImage.Size()
+	x, y := g.freePosition(w, h)
+	s := g.addSprite("Q"+strconv.Itoa(len(g.sprites)), x, y, 0.)
+	s.MoveBy(0, 0)
+}
+
+// deleteSelected deletes all the selected sprites, showing the charge ledger
+func (g *Game) deleteSelected() {
+	if len(g.selected) == 0 {
 		return
 	}
+	before := totalCharge(g)
+	for _, s := range g.selectedSprites() {
+		g.deleteSprite(s)
+	}
+	g.showChargeLedger(before)
+}
 
-	s := stroke.DraggingObject().(*Sprite)
-	if s == nil {
-		return
+// isSelected reports whether a sprite is part of the selection
+func (g *Game) isSelected(s *Sprite) bool {
+	_, ok := g.selected[s]
+	return ok
+}
+
+// selectedSprites returns the selected sprites in the order of g.sprites
+func (g *Game) selectedSprites() []*Sprite {
+	selected := []*Sprite{}
+	for _, s := range g.sprites {
+		if g.isSelected(s) {
+			selected = append(selected, s)
+		}
 	}
+	return selected
+}
 
-	s.MoveBy(stroke.PositionDiff())
+// selectOnly makes s the only selected sprite, or clears the selection if s is nil
+func (g *Game) selectOnly(s *Sprite) {
+	g.selected = map[*Sprite]struct{}{}
+	if s != nil {
+		g.selected[s] = struct{}{}
+	}
+	g.ChosenSprite = s
+}
 
-	index := -1
-	for i, ss := range g.sprites {
-		if ss == s {
-			index = i
+// cycleSelection selects the sprite after the chosen one in g.sprites, or the one before it if step is -1,
+// wrapping around. Without a chosen sprite the first one (or the last one going backwards) is selected.
+func (g *Game) cycleSelection(step int) {
+	n := len(g.sprites)
+	if n == 0 {
+		return
+	}
+	next := 0
+	if step < 0 {
+		next = n - 1
+	}
+	for i, s := range g.sprites {
+		if s == g.ChosenSprite {
+			next = (i + step + n) % n
 			break
 		}
 	}
+	g.selectOnly(g.sprites[next])
+}
 
-	// Move the dragged sprite to the front.
-	g.sprites = append(g.sprites[:index], g.sprites[index+1:]...)
-	g.sprites = append(g.sprites, s)
+// click updates the selection for a click on s, which may be nil.
+// Holding Shift adds s to the selection instead of replacing it.
+func (g *Game) click(s *Sprite) {
+	if !ebiten.IsKeyPressed(ebiten.KeyShift) {
+		g.selectOnly(s)
+		return
+	}
+	g.addToSelection(s)
+}
 
-	stroke.SetDraggingObject(nil)
+// addToSelection adds s to the selected sprites and makes it the chosen one, if it isn't nil
+func (g *Game) addToSelection(s *Sprite) {
+	if s != nil {
+		g.selected[s] = struct{}{}
+		g.ChosenSprite = s
+	}
 }
 
-func (g *Game) update(screen *ebiten.Image) error {
-	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		s := NewStroke(&MouseStrokeSource{})
-		spriteAtPos := g.spriteAt(s.Position())
-		s.SetDraggingObject(spriteAtPos)
-		g.strokes[s] = struct{}{}
-		for _, s := range g.sprites {
-			s.chosen = false
-		}
-		if spriteAtPos != nil {
-			spriteAtPos.chosen = true
-		}
-		g.ChosenSprite = spriteAtPos
+// stepCharge adds delta to the charge of the selected sprites, as a single undoable action.
+// With the sign lock on, the charges stop at zero instead of changing sign, and neutral charges are left
+// untouched until given a sign with setSign.
+func (g *Game) stepCharge(delta float64) {
+	if len(g.selected) == 0 {
+		return
 	}
-	for _, id := range inpututil.JustPressedTouchIDs() {
-		s := NewStroke(&TouchStrokeSource{id})
-		spriteAtPos := g.spriteAt(s.Position())
-		s.SetDraggingObject(spriteAtPos)
-		g.strokes[s] = struct{}{}
-		for _, s := range g.sprites {
-			s.chosen = false
-		}
-		if spriteAtPos != nil {
-			spriteAtPos.chosen = true
+	g.pushUndo()
+	g.addCharge(delta)
+}
+
+// addCharge adds delta to the charge of the selected sprites like stepCharge, as part of the last undoable action.
+// It is used while 'P'/'N' are held, so the whole hold is undone at once.
+func (g *Game) addCharge(delta float64) {
+	for _, s := range g.selectedSprites() {
+		q := s.charge + delta
+		if g.signLock && (s.charge == 0 || q*s.charge < 0 || math.Abs(q) < g.chargeStep/2) {
+			q = 0
 		}
-		g.ChosenSprite = spriteAtPos
+		s.charge = g.capCharge(q)
 	}
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
-		s := &Sprite{
-			name:   "Q" + strconv.Itoa(len(theGame.sprites)),
-			image:  neutralImage,
-			x:      rand.Intn(screenWidth),
-			y:      rand.Intn(screenHeight),
-			charge: 0.,
-		}
-		theGame.sprites = append(theGame.sprites, s)
+// capCharge returns q kept between -maxCharge and maxCharge, showing a notice when it had to be capped,
+// as huge charges make forces too large to draw and the motion unstable
+func (g *Game) capCharge(q float64) float64 {
+	if math.Abs(q) <= g.maxCharge {
+		return q
 	}
+	g.showNotice(fmt.Sprintf("Max charge of %g C reached", g.maxCharge))
+	return math.Copysign(g.maxCharge, q)
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyP) {
-		for _, s := range g.sprites {
-			if s.chosen {
-				s.charge += 0.1
-			}
+// setSign gives the selected sprites the sign of sign, keeping their magnitude.
+// Neutral sprites get a charge of one step.
+func (g *Game) setSign(sign float64) {
+	for _, s := range g.selectedSprites() {
+		if s.charge == 0 {
+			s.charge = g.capCharge(sign * g.chargeStep)
+		} else {
+			s.charge = math.Copysign(s.charge, sign)
 		}
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyN) {
-		for _, s := range g.sprites {
-			if s.chosen {
-				s.charge -= 0.1
-			}
-		}
+}
+
+// keyRepeatSteps returns how many steps a key held down repeats on this tick, like the auto-repeat of a keyboard:
+// none until it has been held for keyRepeatDelay ticks, then one every keyRepeatInterval ticks, twice as many
+// for each further keyRepeatAccelTicks held, up to keyRepeatMaxSteps
+func keyRepeatSteps(key ebiten.Key) float64 {
+	held := inpututil.KeyPressDuration(key) - keyRepeatDelay
+	if held <= 0 || held%keyRepeatInterval != 0 {
+		return 0
 	}
+	return math.Min(math.Pow(2, float64(held/keyRepeatAccelTicks)), keyRepeatMaxSteps)
+}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
-		for _, s := range g.sprites {
-			if s.chosen {
-				s.y -= screenHeight / 10
-			}
+// scaleChargeStep multiplies the charge step by 10 to the power of n, keeping it between minChargeStep
+// and maxChargeStep. The step is rounded to a power of 10 first, so it never drifts with the float errors.
+func (g *Game) scaleChargeStep(n int) {
+	exp := int(math.Round(math.Log10(g.chargeStep))) + n
+	g.chargeStep = math.Max(minChargeStep, math.Min(math.Pow10(exp), maxChargeStep))
+}
+
+// deleteSprite removes a sprite from the game, clearing the selection and cancelling
+// any stroke dragging it so nothing keeps referencing it
+func (g *Game) deleteSprite(s *Sprite) {
+	for i, ss := range g.sprites {
+		if ss == s {
+			g.sprites = append(g.sprites[:i], g.sprites[i+1:]...)
+			break
 		}
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
-		for _, s := range g.sprites {
-			if s.chosen {
-				s.y += screenHeight / 10
-			}
-		}
+	if g.ChosenSprite == s {
+		g.ChosenSprite = nil
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
-		for _, s := range g.sprites {
-			if s.chosen {
-				s.x += screenWidth / 10
-			}
-		}
+	if g.menu != nil && g.menu.sprite == s {
+		g.menu = nil
 	}
-	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
-		for _, s := range g.sprites {
-			if s.chosen {
-				s.x -= screenWidth / 10
-			}
+	g.unlinkMirror(s)
+	if g.isolated != nil && (g.isolated[0] == s || g.isolated[1] == s) {
+		g.isolated = nil
+	}
+	delete(g.selected, s)
+	kept := g.strokes[:0]
+	for _, stroke := range g.strokes {
+		if sprite, ok := stroke.DraggingObject().(*Sprite); !ok || sprite != s {
+			kept = append(kept, stroke)
 		}
 	}
+	g.strokes = kept
+}
 
-	for s := range g.strokes {
-		g.updateStroke(s)
-		if s.IsReleased() {
-			delete(g.strokes, s)
+// hasStroke reports whether stroke is still tracked, as deleting a sprite cancels the strokes dragging it
+func (g *Game) hasStroke(stroke *Stroke) bool {
+	for _, s := range g.strokes {
+		if s == stroke {
+			return true
 		}
 	}
+	return false
+}
 
-	if ebiten.IsDrawingSkipped() {
-		return nil
+// splitChosen splits the chosen sprite into two sprites carrying half of its charge each.
+// The halves are placed side by side and both are left selected, as an undoable action.
+// They are named after it, numbered like copies to keep the names unique.
+// The charge ledger confirms the total charge is conserved.
+func (g *Game) splitChosen() {
+	s := g.ChosenSprite
+	if s == nil {
+		return
 	}
+	g.pushUndo()
+	before := totalCharge(g)
+	w, _ := s.image.Size()
+	half := s.charge / 2
+	nameA, nameB := g.uniqueName(s.name+"a"), g.uniqueName(s.name+"b")
+	s.name = nameA
+	s.charge = half
+	s.mass /= 2
+	other := g.addSprite(nameB, s.x, s.y, half)
+	other.mass = s.mass
+	g.selected[other] = struct{}{}
+	s.MoveBy(-w/2, 0)
+	other.MoveBy(w/2, 0)
+	g.showChargeLedger(before)
+}
 
-	drawHelp(screen)
-	draggingSprites := map[*Sprite]struct{}{}
-	for s := range g.strokes {
-		if sprite := s.DraggingObject().(*Sprite); sprite != nil {
-			draggingSprites[sprite] = struct{}{}
+// copyChosen copies the chosen sprite to the clipboard
+func (g *Game) copyChosen() {
+	s := g.ChosenSprite
+	if s == nil {
+		return
+	}
+	c := s.sceneCharge()
+	g.clipboard = &c
+}
+
+// paste adds a copy of the charge in the clipboard, pasteOffset px further down and right than the previous one,
+// and selects it. The copy is named after the original, numbered to keep the names unique.
+func (g *Game) paste() {
+	c := g.clipboard
+	if c == nil {
+		return
+	}
+	c.X += pasteOffset
+	c.Y += pasteOffset
+	g.addCopy(*c)
+}
+
+// duplicate adds a copy of s pasteOffset px further down and right, leaving the clipboard untouched
+func (g *Game) duplicate(s *Sprite) {
+	c := s.sceneCharge()
+	c.X += pasteOffset
+	c.Y += pasteOffset
+	g.addCopy(c)
+}
+
+// addCopy adds the charge c, named after c.Name but numbered to keep the names unique, and selects it,
+// showing the charge ledger
+func (g *Game) addCopy(c SceneCharge) {
+	before := totalCharge(g)
+	s := g.addSprite(g.uniqueName(c.Name), c.X, c.Y, c.Charge)
+	s.z = c.Z
+	s.mass = c.Mass
+	s.fixed = c.Fixed
+	s.image = imageFor(s.charge)
+	s.MoveBy(0, 0)
+	g.selectOnly(s)
+	g.showChargeLedger(before)
+}
+
+// uniqueName returns name followed by the first number from 2 that no sprite is named with
+func (g *Game) uniqueName(name string) string {
+	taken := map[string]bool{}
+	for _, s := range g.sprites {
+		taken[s.name] = true
+	}
+	for i := 2; ; i++ {
+		if n := name + "-" + strconv.Itoa(i); !taken[n] {
+			return n
+		}
+	}
+}
+
+// createCharge adds the charge defined by a finished creation stroke, centered where the stroke started
+func (g *Game) createCharge(stroke *Stroke, c *ChargeCreation) {
+	dx, dy := stroke.PositionDiff()
+	q := g.capCharge(c.Charge(dx, dy))
+	if q == 0 {
+		return
+	}
+	x, y := stroke.Position()
+	w, h := neutralImage.Size()
+	s := g.addSprite("Q"+strconv.Itoa(len(g.sprites)), x-dx-w/2, y-dy-h/2, q)
+	s.MoveBy(0, 0)
+	g.selectOnly(s)
+}
+
+// drawChargeCreation draws the charge being created by a stroke, linking the start of the drag to the cursor
+func drawChargeCreation(screen *ebiten.Image, stroke *Stroke, c *ChargeCreation) {
+	dx, dy := stroke.PositionDiff()
+	q := c.Charge(dx, dy)
+	if q == 0 {
+		return
+	}
+	x, y := stroke.Position()
+	drawLine(screen, float64(x-dx), float64(y-dy), float64(x), float64(y), lineColor)
+	text.Draw(screen, fmt.Sprintf("%+.2f C", q), theGame.Font, x+fontHeight, y, chargeColor(q))
+}
+
+// toggleRecording starts recording the frames to disk, or stops it if already recording
+func (g *Game) toggleRecording() {
+	if g.recorder != nil {
+		g.recorder.Stop()
+		g.recorder = nil
+		return
+	}
+	r, err := NewRecorder(g.storage)
+	if err != nil {
+		log.Printf("could not start recording: %v", err)
+		return
+	}
+	g.recorder = r
+}
+
+// drawOrder returns the sprites ordered from back to front.
+// In perspective mode the charges further away are drawn first.
+func (g *Game) drawOrder() []*Sprite {
+	if !g.perspective {
+		return g.sprites
+	}
+	sprites := append([]*Sprite{}, g.sprites...)
+	sort.SliceStable(sprites, func(i, j int) bool {
+		return sprites[i].z > sprites[j].z
+	})
+	return sprites
+}
+
+// nearestTo returns the sprite closest to s, or nil if s is the only one
+func (g *Game) nearestTo(s *Sprite) *Sprite {
+	var nearest *Sprite
+	for _, other := range g.sprites {
+		if other != s && (nearest == nil || distance(s, other) < distance(s, nearest)) {
+			nearest = other
+		}
+	}
+	return nearest
+}
+
+// drawForceLaw warns on the bottom right of the playfield when the force law is not the inverse square law
+func drawForceLaw(screen *ebiten.Image) {
+	if theGame.exponent == 2 {
+		return
+	}
+	_, height := theGame.playfield()
+	drawTextRight(screen, "Hypothetical force law: F ~ 1/r"+powerText(theGame.exponent), height, offScaleColor)
+}
+
+// drawTextRight draws a text aligned to the right edge of the screen
+func drawTextRight(screen *ebiten.Image, str string, y int, clr color.Color) {
+	x := theGame.width - font.MeasureString(theGame.Font, str).Ceil() - fullScreenWidth*.01
+	text.Draw(screen, str, theGame.Font, x, y, clr)
+}
+
+// drawHelp draws the help text on the bottom of the screen
+func drawHelp(screen *ebiten.Image) {
+	height := float64(theGame.height)
+	textHeight := int(height - height*.05)
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(float64(theGame.width), height/10)
+	opts.GeoM.Translate(0, height*.9+height*.01)
+	screen.DrawImage(rectangle, opts)
+	text.Draw(screen, "LMB to select (Shift adds), drag, 'A' add, 'D' delete, 'Y' split, 'H' legend. ", theGame.Font, 0, textHeight, color.NRGBA{0xff, 0x00, 0x00, 0xff})
+	text.Draw(screen, fmt.Sprintf("'P'/'N' ±%g C ('['/']' step), Enter set, Shift+Enter move, 'S'/'L' save/load.", theGame.chargeStep), theGame.Font, 0, textHeight+fontHeight+fontHeight/5, color.NRGBA{0xff, 0x00, 0x00, 0xff})
+}
+
+// pushApart moves s out of the sprites it overlaps, along the line going through their centers,
+// so their centers end up at least the sum of their radii apart
+func (g *Game) pushApart(s *Sprite) {
+	// pushing s out of a sprite may push it into another one, so a few passes are made
+	for pass := 0; pass < maxOverlapPasses; pass++ {
+		moved := false
+		for _, other := range g.sprites {
+			if other == s {
+				continue
+			}
+			x1, y1 := s.center()
+			x2, y2 := other.center()
+			minDistance := s.radius() + other.radius()
+			d := math.Hypot(x1-x2, y1-y2)
+			if d >= minDistance {
+				continue
+			}
+			a := 0. // sprites at the same position are pushed apart horizontally
+			if d > 0 {
+				a = math.Atan2(y1-y2, x1-x2)
+			}
+			// rounding away from zero, so s doesn't stay overlapping by a fraction of pixel
+			dx, dy := x2+minDistance*math.Cos(a)-x1, y2+minDistance*math.Sin(a)-y1
+			s.MoveBy(int(math.Copysign(math.Ceil(math.Abs(dx)), dx)), int(math.Copysign(math.Ceil(math.Abs(dy)), dy)))
+			moved = true
+		}
+		if !moved {
+			return
+		}
+	}
+}
+
+// press starts tracking a new stroke, pressing the button or the sprite under it.
+// While the context menu is open the stroke only presses its entries, pressing anywhere else closing it.
+func (g *Game) press(stroke *Stroke) {
+	g.strokes = append(g.strokes, stroke)
+	if g.menu != nil {
+		if item := g.menu.itemAt(stroke.Position()); item != nil {
+			stroke.SetDraggingObject(item)
+		} else {
+			stroke.SetDraggingObject(g.menu)
+			g.menu = nil
+		}
+		return
+	}
+	if b := g.buttonAt(stroke.Position()); b != nil {
+		stroke.SetDraggingObject(b)
+		return
+	}
+	if x, y := stroke.Position(); g.inSlider(x, y) {
+		c := &chargeSlider{g.ChosenSprite}
+		stroke.SetDraggingObject(c)
+		g.pushUndo()
+		g.slide(c, x)
+		return
+	}
+	if s, inside := g.inspectorRowAt(stroke.Position()); inside {
+		stroke.SetDraggingObject(&inspectorRow{s})
+		if s != nil {
+			g.selectOnly(s)
+		}
+		return
+	}
+	spriteAtPos := g.spriteAt(stroke.Position())
+	if g.probeMode && spriteAtPos == nil {
+		stroke.SetDraggingObject(g.toggleProbe(stroke.Position()))
+		return
+	}
+	dragged := g.draggedSprites()
+	if _, ok := dragged[spriteAtPos]; ok {
+		// another finger already drags this sprite, only the first one moves it
+		stroke.SetDraggingObject((*Sprite)(nil))
+		return
+	}
+	stroke.SetDraggingObject(draggingObjectAt(spriteAtPos))
+	if len(dragged) > 0 {
+		// while other fingers drag sprites, a new touch adds to the selection instead of replacing it,
+		// so touches dragging charges at the same time don't fight over it
+		g.addToSelection(spriteAtPos)
+		return
+	}
+	g.click(spriteAtPos)
+}
+
+func (g *Game) updateStroke(stroke *Stroke) {
+	stroke.Update()
+	if c, ok := stroke.DraggingObject().(*chargeSlider); ok {
+		// the charge follows the handle while dragging, not only on release
+		x, _ := stroke.Position()
+		g.slide(c, x)
+		if stroke.IsReleased() {
+			stroke.SetDraggingObject(nil)
+		}
+		return
+	}
+	if !stroke.IsReleased() {
+		return
+	}
+
+	if b, ok := stroke.DraggingObject().(*button); ok {
+		// like most buttons, releasing out of it cancels the press
+		if b.In(stroke.Position()) {
+			b.action(g)
+		}
+		stroke.SetDraggingObject(nil)
+		return
+	}
+
+	if item, ok := stroke.DraggingObject().(*menuItem); ok {
+		// like the buttons, releasing out of the entry cancels the press
+		if m := g.menu; m != nil && m.itemAt(stroke.Position()) == item {
+			g.menu = nil
+			item.action(g, m.sprite)
+		}
+		stroke.SetDraggingObject(nil)
+		return
+	}
+
+	if _, ok := stroke.DraggingObject().(*inspectorRow); ok {
+		// the row was selected on press
+		stroke.SetDraggingObject(nil)
+		return
+	}
+
+	if _, ok := stroke.DraggingObject().(*contextMenu); ok {
+		// the press closing the menu does nothing else
+		stroke.SetDraggingObject(nil)
+		return
+	}
+
+	if c, ok := stroke.DraggingObject().(*ChargeCreation); ok {
+		g.createCharge(stroke, c)
+		stroke.SetDraggingObject(nil)
+		return
+	}
+
+	if _, ok := stroke.DraggingObject().(*probe); ok {
+		// the probe was placed or removed on press
+		stroke.SetDraggingObject(nil)
+		return
+	}
+
+	s := stroke.DraggingObject().(*Sprite)
+	if s == nil {
+		return
+	}
+
+	x, y := s.x, s.y
+	s.MoveBy(stroke.PositionDiff())
+	if g.snapToGrid {
+		g.snapSprite(s)
+	}
+	if g.preventOverlap {
+		g.pushApart(s)
+	}
+	s.clearTrail()
+	if s.mirror != nil {
+		s.mirror.MoveBy(x-s.x, s.y-y)
+		s.mirror.clearTrail()
+	}
+	s.remainderX, s.remainderY = 0, 0
+	if !g.keepDragVelocity {
+		s.vx, s.vy = 0, 0
+	}
+
+	index := -1
+	for i, ss := range g.sprites {
+		if ss == s {
+			index = i
+			break
+		}
+	}
+
+	// Move the dragged sprite to the front.
+	g.sprites = append(g.sprites[:index], g.sprites[index+1:]...)
+	g.sprites = append(g.sprites, s)
+
+	stroke.SetDraggingObject(nil)
+}
+
+// draggedSprites returns the sprites being dragged by a stroke
+func (g *Game) draggedSprites() map[*Sprite]struct{} {
+	dragged := map[*Sprite]struct{}{}
+	for _, s := range g.strokes {
+		if sprite, ok := s.DraggingObject().(*Sprite); ok && sprite != nil {
+			dragged[sprite] = struct{}{}
+		}
+	}
+	return dragged
+}
+
+// updateKeys handles the keyboard shortcuts
+func (g *Game) updateKeys() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && g.ChosenSprite != nil {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.startInput(inputPosition)
+		} else {
+			g.startInput(inputCharge)
+		}
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) && g.ChosenSprite != nil {
+		g.startInput(inputName)
+		return
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		g.addRandomCharge()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyTab) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.cycleSelection(-1)
+		} else {
+			g.cycleSelection(1)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		g.Reset()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyY) {
+		g.splitChosen()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		if err := g.Save(sceneFileName); err != nil {
+			log.Printf("could not save the scene: %v", err)
+		} else {
+			log.Printf("scene saved to %s", sceneFileName)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) && ebiten.IsKeyPressed(ebiten.KeyControl) {
+		g.toggleMirror()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyL) && ebiten.IsKeyPressed(ebiten.KeyShift) {
+		g.startInput(inputLine)
+		return
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		if err := g.Load(sceneFileName); err != nil {
+			log.Printf("could not load the scene: %v", err)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyD) || inpututil.IsKeyJustPressed(ebiten.KeyDelete) {
+		g.deleteSelected()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyC) && ebiten.IsKeyPressed(ebiten.KeyControl) {
+		g.copyChosen()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyC) && ebiten.IsKeyPressed(ebiten.KeyShift) {
+		if path, err := g.SaveSVG(); err != nil {
+			log.Printf("could not export the SVG: %v", err)
+		} else {
+			log.Printf("SVG exported to %s", path)
+		}
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyC) {
+		if path, err := g.SaveScreenshot(); err != nil {
+			log.Printf("could not save the screenshot: %v", err)
+		} else {
+			log.Printf("screenshot saved to %s", path)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyX) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			if path, err := g.SaveForcesCSV(); err != nil {
+				log.Printf("could not export the forces: %v", err)
+			} else {
+				log.Printf("forces exported to %s", path)
+			}
+		} else {
+			for _, s := range g.selectedSprites() {
+				s.fixed = !s.fixed
+				s.vx, s.vy = 0, 0
+			}
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF9) {
+		g.toggleRecording()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyZ) && ebiten.IsKeyPressed(ebiten.KeyControl) {
+		g.popUndo()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyZ) {
+		g.perspective = !g.perspective
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyI) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.toggleIsolate()
+		} else {
+			g.invertCharges()
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyW) && ebiten.IsKeyPressed(ebiten.KeyShift) {
+		g.startInput(inputCapacitor)
+		return
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyW) {
+		g.ArrangeCircle()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			if g.ChosenSprite != nil {
+				g.startInput(inputMass)
+				return
+			}
+		} else {
+			g.probeMode = !g.probeMode
+			g.contourLevel = nil
+		}
+	}
+	if g.perspective && inpututil.IsKeyJustPressed(ebiten.KeyPageUp) {
+		for _, s := range g.selectedSprites() {
+			s.MoveDepthBy(depthStep)
+		}
+	}
+	if g.perspective && inpututil.IsKeyJustPressed(ebiten.KeyPageDown) {
+		for _, s := range g.selectedSprites() {
+			s.MoveDepthBy(-depthStep)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.Key9) {
+		g.exponent = math.Max(g.exponent-exponentStep, minExponent)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.Key0) {
+		g.exponent = math.Min(g.exponent+exponentStep, maxExponent)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.showFieldLines = !g.showFieldLines
+		} else {
+			g.showFieldGrid = !g.showFieldGrid
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyG) {
+		g.snapToGrid = !g.snapToGrid
+	}
+	plus := inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyKPAdd)
+	minus := inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyKPSubtract)
+	switch {
+	case plus && ebiten.IsKeyPressed(ebiten.KeyControl):
+		g.setFontSize(g.fontSize + fontSizeStep)
+	case minus && ebiten.IsKeyPressed(ebiten.KeyControl):
+		g.setFontSize(g.fontSize - fontSizeStep)
+	case plus:
+		g.resizeGrid(gridSizeStep)
+	case minus:
+		g.resizeGrid(-gridSizeStep)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) && ebiten.IsKeyPressed(ebiten.KeyControl) {
+		g.paste()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyV) && ebiten.IsKeyPressed(ebiten.KeyShift) && g.probeMode {
+		g.snapContour(ebiten.CursorPosition())
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		g.showEquipotentials = !g.showEquipotentials
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		g.playing = !g.playing
+		g.relaxing = false
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
+		if g.relaxing {
+			g.relaxing = false
+		} else {
+			g.startRelaxing()
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyB) {
+		g.boundary = g.boundary.next()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyComma) {
+		g.timeScale = math.Max(g.timeScale/timeScaleStep, minTimeScale)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyPeriod) {
+		g.timeScale = math.Min(g.timeScale*timeScaleStep, maxTimeScale)
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyK) {
+		g.showNames = !g.showNames
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyO) {
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			g.overlayOpacity = math.Min(g.overlayOpacity+opacityStep, 1)
+		} else {
+			g.overlayOpacity = math.Max(g.overlayOpacity-opacityStep, opacityStep)
+		}
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		g.showLegend = !g.showLegend
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
+		g.showInspector = !g.showInspector
+	}
+	if g.recoveryOffered && inpututil.IsKeyJustPressed(ebiten.KeyF12) {
+		g.answerRecovery(!ebiten.IsKeyPressed(ebiten.KeyShift))
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF10) {
+		g.gravity = !g.gravity
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF8) {
+		g.sizeByCharge = !g.sizeByCharge
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF7) {
+		g.showForceArrows = !g.showForceArrows
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyU) {
+		g.netForcesOnly = !g.netForcesOnly
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyJ) {
+		g.rotateSprites = !g.rotateSprites
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF6) {
+		g.showHeatmap = !g.showHeatmap
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF5) {
+		g.showTrails = !g.showTrails
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF4) {
+		g.preventOverlap = !g.preventOverlap
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF2) {
+		g.signLock = !g.signLock
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyF3) {
+		g.showDiagnostics = !g.showDiagnostics
+	}
+	for _, key := range []ebiten.Key{ebiten.KeyP, ebiten.KeyN} {
+		sign := 1.
+		if key == ebiten.KeyN {
+			sign = -1
+		}
+		step := sign * g.chargeStep
+		if ebiten.IsKeyPressed(ebiten.KeyShift) {
+			step *= coarseChargeSteps
+		}
+		switch {
+		case inpututil.IsKeyJustPressed(key) && ebiten.IsKeyPressed(ebiten.KeyControl):
+			g.setSign(sign)
+		case inpututil.IsKeyJustPressed(key):
+			g.stepCharge(step)
+		case !ebiten.IsKeyPressed(ebiten.KeyControl) && len(g.selected) > 0:
+			// held down, the charge keeps changing like an auto-repeating key
+			if n := keyRepeatSteps(key); n > 0 {
+				g.addCharge(n * step)
+			}
 		}
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeftBracket) {
+		g.scaleChargeStep(-1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRightBracket) {
+		g.scaleChargeStep(1)
+	}
+
+	width, height := g.playfield()
+	// the arrows move by a tenth of the playfield, or by fineMoveStep px with Shift (by a cell of the snap grid
+	// when snapping, as smaller moves would be snapped back)
+	stepX, stepY := width/10, height/10
+	if ebiten.IsKeyPressed(ebiten.KeyShift) {
+		stepX, stepY = fineMoveStep, fineMoveStep
+		if g.snapToGrid {
+			stepX, stepY = g.gridSize, g.gridSize
+		}
+	}
+	dx, dy := 0, 0
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) {
+		dy -= stepY
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) {
+		dy += stepY
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		dx += stepX
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		dx -= stepX
+	}
+	if dx != 0 || dy != 0 {
+		// snapped once moved, like the dragged sprites on drop, so the charges in motion aren't held on the grid
+		for _, s := range g.selectedSprites() {
+			s.MoveBy(dx, dy)
+			if g.snapToGrid {
+				g.snapSprite(s)
+			}
+		}
+	}
+}
+
+// kScale is the ratio between the game k and the SI one used by the charges package
+func (g *Game) kScale() float64 {
+	if g.gravity {
+		// a negative constant turns the repulsion of the masses, all positive, into an attraction
+		return -g.k / charges.K
+	}
+	return g.k / charges.K
+}
+
+// updateGrid hashes the current positions of the charges in the spatial grid
+func (g *Game) updateGrid() {
+	particles := make([]charges.Particle, len(g.sprites))
+	for i, s := range g.sprites {
+		particles[i] = s.particle()
+	}
+	g.grid = charges.NewGrid(particles, spatialCellSize, spatialCutoff, exactForcesThreshold)
+}
+
+// Layout follows the size of the window, rescaling the playfield when it changes
+func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if outsideWidth != g.width || outsideHeight != g.height {
+		g.resize(outsideWidth, outsideHeight)
+	}
+	return g.width, g.height
+}
+
+// Update handles the input and advances the game by one tick, without drawing anything
+func (g *Game) Update() error {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		g.press(NewStroke(&MouseStrokeSource{}))
+	}
+	for _, id := range inpututil.JustPressedTouchIDs() {
+		g.press(NewStroke(&TouchStrokeSource{id}))
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		x, y := ebiten.CursorPosition()
+		g.menu = nil
+		if s := g.spriteAt(x, y); s != nil {
+			g.openContextMenu(s, x, y)
+		}
+	}
+
+	if g.inputMode != inputNone {
+		g.updateInput()
+	} else {
+		g.updateKeys()
+	}
+
+	if g.relaxing {
+		g.relax()
+	}
+	if g.playing {
+		g.step()
+		g.pausedTicks = 0
+		g.energy.add(totalEnergy(g))
+	} else {
+		g.energy.clear()
+		g.pausedTicks++
+		if g.pausedTicks == trailClearTicks {
+			for _, s := range g.sprites {
+				s.clearTrail()
+			}
+		}
+	}
+
+	// updating a stroke may delete a sprite and cancel the strokes dragging it, so a copy is ranged over
+	for _, s := range append([]*Stroke{}, g.strokes...) {
+		if g.hasStroke(s) {
+			g.updateStroke(s)
+		}
+	}
+	pressed := g.strokes[:0]
+	for _, s := range g.strokes {
+		if !s.IsReleased() {
+			pressed = append(pressed, s)
+		}
+	}
+	g.strokes = pressed
 
 	for _, s := range g.sprites {
+		s.image = imageFor(s.charge)
+		if g.sizeByCharge {
+			// a growing charge stays inside the playfield
+			s.MoveBy(0, 0)
+		}
+	}
+	g.updateGrid()
+	g.updateBreakdown()
+	g.updateInspector()
+	g.updateNotice()
+	g.updateAutosave()
+	return nil
+}
+
+// Draw draws the game on the screen, capturing it if recording
+func (g *Game) Draw(screen *ebiten.Image) {
+	g.draw(screen)
+	if g.recorder != nil {
+		g.recorder.Capture(screen)
+	}
+}
+
+// draw draws the whole game on screen, which may be the window or an offscreen image
+func (g *Game) draw(screen *ebiten.Image) {
+	// the heatmap is the background, drawn before anything else
+	if g.showHeatmap {
+		drawFieldHeatmap(screen, g)
+	}
+	drawHelp(screen)
+	drawForceLaw(screen)
+	drawReadouts(screen, g)
+	if g.showDiagnostics {
+		drawDiagnostics(screen, g)
+	}
+	if g.playing {
+		_, height := g.playfield()
+		drawTextRight(screen, fmt.Sprintf("Motion ×%g, edges %s ('Space' pause, '<'/'>' speed, 'B' edges)", g.timeScale, g.boundary), height-fontHeight-fontHeight/2, color.White)
+	}
+	if g.probeMode {
+		_, height := g.playfield()
+		drawTextRight(screen, "Probe mode: click to place or remove probes ('M' to leave)", height-3*fontHeight, probeColor)
+		drawTextRight(screen, contourReadout(g), height-4*fontHeight-fontHeight/2, probeColor)
+	}
+	if g.relaxing {
+		_, height := g.playfield()
+		drawTextRight(screen, fmt.Sprintf("Finding an equilibrium, step %d ('Q' to stop)", g.relaxSteps), height-fontHeight-fontHeight/2, color.White)
+	}
+	if g.snapToGrid {
+		drawGrid(screen, g)
+	}
+	if g.showEquipotentials {
+		drawEquipotentials(screen, g, defaultPotentialLevels())
+	}
+	if g.probeMode && g.contourLevel != nil {
+		drawEquipotentials(screen, g, []float64{*g.contourLevel})
+	}
+	if g.showFieldGrid {
+		drawFieldGrid(screen, g, fieldGridSpacing)
+	}
+	if g.showFieldLines {
+		drawFieldLines(screen, g)
+	}
+	draggingSprites := g.draggedSprites()
+
+	if g.showTrails {
+		for _, s := range g.sprites {
+			s.drawTrail(screen)
+		}
+	}
+	drawRuler(screen, g)
+	drawMirrors(screen, g)
+	if g.playing {
+		drawEnergyPlot(screen, g)
+	}
+	drawNullPoint(screen, g)
+	for _, s := range g.drawOrder() {
 		if _, ok := draggingSprites[s]; ok {
 			continue
 		}
-		switch {
-		case s.charge > 0.:
-			s.image = positiveImage
-		case s.charge < 0.:
-			s.image = negativeImage
-		default:
-			s.image = neutralImage
-		}
 		s.Draw(screen, 0, 0, 1)
+		s.drawVelocity(screen)
 
-		if s.chosen {
+		if s == g.ChosenSprite {
 			s.DrawStatistics(screen, fullScreenWidth*.01, fullScreenHeight*.05, 1)
 		}
-		if g.ChosenSprite != nil && g.ChosenSprite != s {
-			drawElectricalInformation(screen, g.ChosenSprite, s)
+	}
+	for _, p := range g.infoPairs() {
+		// the charges dragged away from the chosen one are drawn by their strokes, without their information
+		if _, ok := draggingSprites[p[1]]; ok && len(g.selected) < 2 {
+			continue
 		}
+		drawElectricalInformation(screen, p[0], p[1])
 	}
-	for s := range g.strokes {
-		dx, dy := s.PositionDiff()
-		if sprite := s.DraggingObject().(*Sprite); sprite != nil {
+	for _, s := range g.strokes {
+		if sprite, ok := s.DraggingObject().(*Sprite); ok && sprite != nil {
+			dx, dy := s.SmoothPositionDiff()
 			sprite.Draw(screen, dx, dy, 0.5)
+			if sprite.mirror != nil {
+				sprite.mirror.Draw(screen, -dx, dy, 0.5)
+			}
+		}
+		if c, ok := s.DraggingObject().(*ChargeCreation); ok {
+			drawChargeCreation(screen, s, c)
 		}
 	}
-	return nil
+	if g.showForceArrows || g.netForcesOnly {
+		drawForceArrows(screen, g)
+	}
+	if g.ChosenSprite != nil {
+		drawNetForce(screen, g, g.ChosenSprite)
+		drawSubsetForce(screen, g, g.ChosenSprite)
+		if nearest := g.nearestTo(g.ChosenSprite); nearest != nil {
+			drawEquation(screen, g.ChosenSprite, nearest, fullScreenWidth*.01, fullScreenHeight*.05+fontHeight*4)
+		}
+	}
+	drawProbes(screen, g)
+	drawHoverProbe(screen, g)
+	drawSlider(screen, g)
+	if g.showInspector {
+		drawInspector(screen, g)
+	} else {
+		drawBreakdown(screen, g)
+	}
+	drawButtons(screen, g)
+	if g.showLegend {
+		drawLegend(screen, g)
+	}
+	drawIsolated(screen, g)
+	drawNotice(screen, g)
+	drawContextMenu(screen, g)
+	g.drawInput(screen)
+}
+
+// Runner is implemented by games split in the Update, Draw and Layout steps of the ebiten.Game interface
+type Runner interface {
+	Update() error
+	Draw(screen *ebiten.Image)
+	Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int)
+}
+
+// run drives r with the single update function of ebiten.Run,
+// as the vendored Ebiten version predates ebiten.RunGame
+func run(r Runner, width, height int, title string) error {
+	return ebiten.Run(func(screen *ebiten.Image) error {
+		r.Layout(screen.Size())
+		if err := r.Update(); err != nil {
+			return err
+		}
+		if ebiten.IsDrawingSkipped() {
+			return nil
+		}
+		r.Draw(screen)
+		return nil
+	}, width, height, 1, title)
 }
 
 func main() {
-	if err := ebiten.Run(theGame.update, fullScreenWidth, fullScreenHeight, 1, "Electrical Charges demonstration"); err != nil {
+	flag.Float64Var(&theGame.k, "k", charges.K, "Coulomb's constant, in Nm²/C² or the units matching -unit-scale")
+	flag.Float64Var(&theGame.unitScale, "unit-scale", defaultUnitScale, "px on screen for each unit of length; changing it changes the meaning of \"1 m\" on screen")
+	flag.IntVar(&theGame.trailLength, "trail-length", defaultTrailLength, "positions kept in the trail of each moving charge")
+	flag.Int64Var(&theGame.seed, "seed", theGame.seed, "seed placing the charges, random by default; set it for reproducible runs")
+	scene := flag.String("scene", "", "scene file to load at start, as saved with 'S'")
+	spritesDir := flag.String("sprites-dir", "", "directory with positive.png, negative.png and neutral.png replacing the charge images")
+	flag.Float64Var(&theGame.maxCharge, "max-charge", defaultMaxCharge, "largest magnitude in C given to a charge while editing")
+	flag.Float64Var(&theGame.dragSmoothing, "drag-smoothing", 1, "fraction of the way to the cursor the dragged charges are drawn moving each tick, below 1 to smooth out jittery touch input")
+	flag.DurationVar(&theGame.autosave, "autosave", 0, "save the scene to "+recoveryFileName+" this often, like 30s, offering to load it on the next launch; off by default")
+	flag.BoolVar(&theGame.keepDragVelocity, "keep-drag-velocity", false, "let the dragged charges keep their velocity in motion mode when dropped, instead of stopping them")
+	flag.Parse()
+	if theGame.unitScale <= 0 {
+		log.Fatalf("-unit-scale must be positive, got %g", theGame.unitScale)
+	}
+	if theGame.maxCharge <= 0 {
+		log.Fatalf("-max-charge must be positive, got %g", theGame.maxCharge)
+	}
+	if theGame.autosave < 0 {
+		log.Fatalf("-autosave must not be negative, got %v", theGame.autosave)
+	}
+	if theGame.dragSmoothing <= 0 || theGame.dragSmoothing > 1 {
+		log.Fatalf("-drag-smoothing must be more than 0 and at most 1, got %g", theGame.dragSmoothing)
+	}
+	if *spritesDir != "" {
+		if err := loadSpriteImages(*spritesDir); err != nil {
+			log.Printf("using the embedded charge images: %v", err)
+		}
+	}
+	log.Printf("using seed %d", theGame.seed)
+	theGame.Reset()
+	if *scene != "" {
+		if err := theGame.Load(*scene); err != nil {
+			log.Fatalf("could not load the scene: %v", err)
+		}
+	}
+	if theGame.autosave > 0 {
+		theGame.offerRecovery()
+	}
+
+	if err := run(theGame, fullScreenWidth, fullScreenHeight, "Electrical Charges demonstration"); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auyer/electrical-charges/charges"
+	"github.com/hajimehoshi/ebiten"
+	"golang.org/x/image/font"
+)
+
+func TestDeleteSprite(t *testing.T) {
+	g := &Game{}
+	q0 := g.addSprite("Q0", 0, 0, 0)
+	q1 := g.addSprite("Q1", 100, 0, 0)
+	q2 := g.addSprite("Q2", 200, 0, 0)
+	g.ChosenSprite = q1
+	stroke := &Stroke{draggingObject: q1}
+	g.strokes = append(g.strokes, stroke)
+
+	g.deleteSprite(q1)
+	if len(g.sprites) != 2 || g.sprites[0] != q0 || g.sprites[1] != q2 {
+		t.Fatalf("expected [Q0 Q2] after deleting Q1, got %v", g.sprites)
+	}
+	if g.ChosenSprite != nil {
+		t.Errorf("expected no chosen sprite after deleting it, got %s", g.ChosenSprite.name)
+	}
+	if g.hasStroke(stroke) {
+		t.Errorf("expected the stroke dragging Q1 to be cancelled")
+	}
+
+	// removing the last element must not panic
+	g.deleteSprite(q2)
+	if len(g.sprites) != 1 || g.sprites[0] != q0 {
+		t.Fatalf("expected [Q0] after deleting Q2, got %v", g.sprites)
+	}
+}
+
+// fakeStrokeSource is a StrokeSource held still at a position, like a finger on a touchscreen
+type fakeStrokeSource struct {
+	x, y int
+}
+
+func (f *fakeStrokeSource) Position() (int, int) { return f.x, f.y }
+func (f *fakeStrokeSource) IsJustReleased() bool { return false }
+
+func TestSimultaneousTouches(t *testing.T) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight}
+	q0 := g.addSprite("Q0", 0, 0, 1)
+	q1 := g.addSprite("Q1", 200, 0, -1)
+	w, h := q0.image.Size()
+	first := NewStroke(&fakeStrokeSource{w / 2, h / 2})
+	second := NewStroke(&fakeStrokeSource{200 + w/2, h / 2})
+	third := NewStroke(&fakeStrokeSource{w / 2, h / 2})
+	g.press(first)
+	g.press(second)
+	g.press(third)
+
+	if first.DraggingObject() != q0 || second.DraggingObject() != q1 {
+		t.Errorf("expected each touch to drag its own sprite, got %v and %v", first.DraggingObject(), second.DraggingObject())
+	}
+	if sprite, ok := third.DraggingObject().(*Sprite); !ok || sprite != nil {
+		t.Errorf("expected a second touch on Q0 not to drag it, got %v", third.DraggingObject())
+	}
+	if len(g.selected) != 2 {
+		t.Errorf("expected both touched sprites to stay selected, got %d selected", len(g.selected))
+	}
+}
+
+func TestForceBetweenUnitCharges(t *testing.T) {
+	// 1 m is 100 px on screen
+	q1 := &Sprite{x: 0, y: 0, charge: 1}
+	q2 := &Sprite{x: 100, y: 0, charge: 1}
+	if d := distance(q1, q2); d != 1 {
+		t.Fatalf("expected the charges to be 1 m apart, got %g m", d)
+	}
+	if f := force(q1, q2); math.Abs(f-8.9875517923e9) > 1e3 {
+		t.Errorf("expected a force of 8.99e9 N between two 1 C charges 1 m apart, got %g N", f)
+	}
+}
+
+func TestSubsetForce(t *testing.T) {
+	g := &Game{k: charges.K, exponent: 2}
+	q0 := g.addSprite("Q0", 200, 0, 1)
+	q1 := g.addSprite("Q1", 300, 0, 1)
+	g.addSprite("Q2", 100, 0, -1)
+	g.selectOnly(q0)
+	if _, _, ok := subsetForce(g, q0); ok {
+		t.Errorf("expected no subset force without other selected charges")
+	}
+	g.selected[q1] = struct{}{}
+	// only Q1, 1 m to the right, pushes Q0: the attraction of Q2 is left out
+	fx, fy, ok := subsetForce(g, q0)
+	if !ok || math.Abs(fx+charges.K) > 1e-6*charges.K || fy != 0 {
+		t.Errorf("expected a force of (%g, 0) N from Q1 only, got (%g, %g) N", -charges.K, fx, fy)
+	}
+	g.selected[g.sprites[2]] = struct{}{}
+	if _, _, ok := subsetForce(g, q0); ok {
+		t.Errorf("expected no subset force with all the charges selected, as it is the net force")
+	}
+}
+
+func TestForceAtZeroSeparation(t *testing.T) {
+	q1 := &Sprite{x: 10, y: 10, charge: 1}
+	q2 := &Sprite{x: 10, y: 10, charge: -1}
+	if d := distance(q1, q2); d <= 0 {
+		t.Errorf("expected a positive distance between coincident charges, got %g m", d)
+	}
+	if f := force(q1, q2); math.IsInf(f, 0) || math.IsNaN(f) {
+		t.Errorf("expected a finite force between coincident charges, got %g N", f)
+	}
+}
+
+func TestExportForcesCSV(t *testing.T) {
+	g := &Game{}
+	g.addSprite("A", 0, 0, 1)
+	g.addSprite("B", 300, 400, -1)
+	g.addSprite("C", 0, 100, 0)
+
+	b := &bytes.Buffer{}
+	if err := g.ExportForcesCSV(b); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(b).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("expected a header and 3 pairs, got %d rows", len(rows))
+	}
+	if rows[1][0] != "A" || rows[1][1] != "B" {
+		t.Fatalf("expected the first pair to be A and B, got %v", rows[1])
+	}
+	// A and B are 300 px and 400 px apart along x and y, so 5 m apart
+	if rows[1][4] != "5" {
+		t.Errorf("expected a separation of 5 m, got %s m", rows[1][4])
+	}
+	if f, _ := strconv.ParseFloat(rows[1][5], 64); math.Abs(f+theGame.k/25) > 1 {
+		t.Errorf("expected an attraction of %g N, got %s N", theGame.k/25, rows[1][5])
+	}
+}
+
+func BenchmarkHeatmapPixels(b *testing.B) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight, unitScale: defaultUnitScale}
+	for i := 0; i < 10; i++ {
+		g.addSprite("Q"+strconv.Itoa(i), i*70, i*50, float64(i%3-1))
+	}
+	width, height := g.playfield()
+	for i := 0; i < b.N; i++ {
+		heatmapPixels(g, width/heatmapCellSize, height/heatmapCellSize)
+	}
+}
+
+func TestAddedChargesStayInPlayfield(t *testing.T) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight, rand: rand.New(rand.NewSource(1))}
+	width, height := g.playfield()
+	for i := 0; i < 100; i++ {
+		g.addRandomCharge()
+	}
+	for _, s := range g.sprites {
+		w, h := s.image.Size()
+		if s.x < 0 || s.y < 0 || s.x+w > width || s.y+h > height {
+			t.Errorf("expected %s fully inside the %dx%d playfield, got it at (%d, %d)", s.name, width, height, s.x, s.y)
+		}
+	}
+}
+
+func TestAddedChargesDontOverlap(t *testing.T) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight, rand: rand.New(rand.NewSource(1))}
+	for i := 0; i < 20; i++ {
+		g.addRandomCharge()
+	}
+	for i, s1 := range g.sprites {
+		for _, s2 := range g.sprites[i+1:] {
+			w, h := s1.image.Size()
+			if s1.x < s2.x+w && s2.x < s1.x+w && s1.y < s2.y+h && s2.y < s1.y+h {
+				t.Errorf("expected %s at (%d, %d) not to overlap %s at (%d, %d)", s1.name, s1.x, s1.y, s2.name, s2.x, s2.y)
+			}
+		}
+	}
+}
+
+func TestCapacitorStaysInPlayfield(t *testing.T) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight, rand: rand.New(rand.NewSource(1))}
+	width, height := g.playfield()
+	// far more charges and a wider gap than fit, so both are reduced
+	g.CreateCapacitor(maxLineCharges, 10*height, 100, 1)
+	if len(g.sprites) != 2*maxLineCharges {
+		t.Fatalf("expected %d charges, got %d", 2*maxLineCharges, len(g.sprites))
+	}
+	for i, s := range g.sprites {
+		w, h := s.image.Size()
+		if s.x < 0 || s.y < 0 || s.x+w > width || s.y+h > height {
+			t.Errorf("expected %s fully inside the %dx%d playfield, got it at (%d, %d)", s.name, width, height, s.x, s.y)
+		}
+		if want := 1 - 2*float64(i/maxLineCharges); s.charge != want {
+			t.Errorf("expected %s to have %g C, got %g C", s.name, want, s.charge)
+		}
+	}
+}
+
+func TestSplitConservesChargeAndStaysInPlayfield(t *testing.T) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight}
+	width, height := g.playfield()
+	w, h := neutralImage.Size()
+	for _, pos := range [][2]int{{0, 0}, {width - w, height - h}, {width / 2, height / 2}} {
+		g.sprites = nil
+		s := g.addSprite("Q", pos[0], pos[1], 3)
+		s.mass = 2
+		g.selectOnly(s)
+		g.splitChosen()
+		if len(g.sprites) != 2 {
+			t.Fatalf("expected 2 halves, got %d sprites", len(g.sprites))
+		}
+		q, mass := 0., 0.
+		for _, half := range g.sprites {
+			q += half.charge
+			mass += half.mass
+			if half.x < 0 || half.y < 0 || half.x+w > width || half.y+h > height {
+				t.Errorf("splitting at (%d, %d): expected %s fully inside the %dx%d playfield, got it at (%d, %d)", pos[0], pos[1], half.name, width, height, half.x, half.y)
+			}
+		}
+		if q != 3 || mass != 2 {
+			t.Errorf("splitting at (%d, %d): expected 3 C and 2 kg in total, got %g C and %g kg", pos[0], pos[1], q, mass)
+		}
+		if len(g.selected) != 2 {
+			t.Errorf("splitting at (%d, %d): expected both halves selected, got %d selected", pos[0], pos[1], len(g.selected))
+		}
+		if want := "Total charge +3.00 C → +3.00 C, conserved"; g.notice != want {
+			t.Errorf("splitting at (%d, %d): expected the ledger %q, got %q", pos[0], pos[1], want, g.notice)
+		}
+	}
+	// each split is undone on its own
+	g.popUndo()
+	if len(g.sprites) != 1 || g.sprites[0].charge != 3 {
+		t.Errorf("expected undoing the split to bring back the single 3 C charge, got %d sprites", len(g.sprites))
+	}
+}
+
+func TestChargeLedger(t *testing.T) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight}
+	q0 := g.addSprite("Q0", 0, 0, 1)
+	g.addSprite("Q1", 100, 0, -2)
+	g.selectOnly(q0)
+	g.duplicate(q0)
+	if !strings.HasSuffix(g.notice, "changed by +1.00 C") {
+		t.Errorf("expected the ledger to tell the duplicate added 1 C, got %q", g.notice)
+	}
+	g.deleteSelected()
+	if want := "Total charge +0.00 C → -1.00 C, changed by -1.00 C"; g.notice != want {
+		t.Errorf("expected the ledger %q after deleting the duplicate, got %q", want, g.notice)
+	}
+}
+
+func TestMoveByClamps(t *testing.T) {
+	width, height := theGame.playfield()
+	w, h := neutralImage.Size()
+	tests := []struct {
+		name   string
+		x, y   int
+		dx, dy int
+		wantX  int
+		wantY  int
+	}{
+		{"inside", 100, 100, 10, -10, 110, 90},
+		{"past the left edge", 10, 100, -50, 0, 0, 100},
+		{"past the top edge", 100, 10, 0, -50, 100, 0},
+		{"past the right edge", width - w - 10, 100, 50, 0, width - w, 100},
+		{"past the bottom edge", 100, height - h - 10, 0, 50, 100, height - h},
+		{"exactly at the left and top edges", 10, 10, -10, -10, 0, 0},
+		{"exactly at the right and bottom edges", 10, 10, width - w - 10, height - h - 10, width - w, height - h},
+		{"past two edges", 10, 10, 10000, -10000, width - w, 0},
+	}
+	for _, tt := range tests {
+		s := &Sprite{name: tt.name, image: neutralImage, x: tt.x, y: tt.y}
+		s.MoveBy(tt.dx, tt.dy)
+		if s.x != tt.wantX || s.y != tt.wantY {
+			t.Errorf("%s: expected (%d, %d), got (%d, %d)", tt.name, tt.wantX, tt.wantY, s.x, s.y)
+		}
+	}
+}
+
+func TestMoveByClampsToResizedPlayfield(t *testing.T) {
+	defer func(width, height int) { theGame.width, theGame.height = width, height }(theGame.width, theGame.height)
+	theGame.width, theGame.height = 400, 300
+	width, height := theGame.playfield()
+	w, h := neutralImage.Size()
+
+	s := &Sprite{image: neutralImage}
+	s.MoveBy(1000, 1000)
+	if s.x != width-w || s.y != height-h {
+		t.Errorf("expected (%d, %d) in the %dx%d playfield, got (%d, %d)", width-w, height-h, width, height, s.x, s.y)
+	}
+}
+
+func TestMoveByKeepsLargeSpriteAtTopLeft(t *testing.T) {
+	defer func(width, height int) { theGame.width, theGame.height = width, height }(theGame.width, theGame.height)
+	w, h := neutralImage.Size()
+	// a playfield smaller than the sprite, the playfield being 9/10 of the height
+	theGame.width, theGame.height = w/2, h/2
+
+	s := &Sprite{image: neutralImage, x: 5, y: 5}
+	s.MoveBy(10, 10)
+	if s.x != 0 || s.y != 0 {
+		t.Errorf("expected a sprite larger than the playfield at (0, 0), got (%d, %d)", s.x, s.y)
+	}
+}
+
+func TestMoveByKeepsScaledSpriteOnScreen(t *testing.T) {
+	defer func(sizeByCharge bool) { theGame.sizeByCharge = sizeByCharge }(theGame.sizeByCharge)
+	theGame.sizeByCharge = true
+	width, height := theGame.playfield()
+	w, h := neutralImage.Size()
+
+	// charges scaled by fractions of pixel must not be drawn past the edges, even by a fraction of pixel
+	for _, charge := range []float64{0.03, 0.15, 0.37, 1, 5} {
+		s := &Sprite{image: neutralImage, charge: charge}
+		scale := s.scale()
+		for _, d := range []int{-10000, 10000} {
+			s.MoveBy(d, d)
+			left := float64(s.x) + float64(w)*(1-scale)/2
+			top := float64(s.y) + float64(h)*(1-scale)/2
+			right, bottom := left+float64(w)*scale, top+float64(h)*scale
+			if left < 0 || top < 0 || right > float64(width) || bottom > float64(height) {
+				t.Errorf("charge %g C: expected the sprite inside the %dx%d playfield, got it drawn from (%g, %g) to (%g, %g)",
+					charge, width, height, left, top, right, bottom)
+			}
+		}
+	}
+}
+
+func TestTotalCharge(t *testing.T) {
+	tests := []struct {
+		name    string
+		charges []float64
+		want    float64
+	}{
+		{"no charges", nil, 0},
+		{"dipole", []float64{1, -1}, 0},
+		{"float errors cancel out", []float64{0.1, 0.2, -0.3}, 0},
+		{"positive", []float64{0.5, -0.2}, 0.3},
+		{"negative", []float64{-1, -1, 0}, -2},
+	}
+	for _, tt := range tests {
+		g := &Game{}
+		for i, q := range tt.charges {
+			g.addSprite("Q"+strconv.Itoa(i), 0, 0, q)
+		}
+		if got := totalCharge(g); math.Abs(got-tt.want) > 1e-12 {
+			t.Errorf("%s: expected %g C, got %g C", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestLabelPositionStaysInPlayfield(t *testing.T) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight}
+	width, height := g.playfield()
+	w, h := neutralImage.Size()
+	lineSpacing, offset, textWidth := fontHeight+fontHeight/10, fontHeight*4, 200
+	tests := []struct {
+		name  string
+		x, y  int
+		flip  bool
+		xWant int
+	}{
+		{"room below", 100, 100, false, 100},
+		{"bottom edge", 100, height - h, true, 100},
+		{"right edge", width - w, 100, false, width - textWidth},
+	}
+	for _, tt := range tests {
+		s := g.addSprite(tt.name, tt.x, tt.y, 0)
+		x, y := labelPosition(g, s, textWidth, 2, offset, lineSpacing)
+		if x != tt.xWant {
+			t.Errorf("%s: expected the labels at x = %d, got %d", tt.name, tt.xWant, x)
+		}
+		if flipped := y < s.y; flipped != tt.flip {
+			t.Errorf("%s: expected the labels flipped above the sprite to be %v, got them at y = %d for a sprite at %d", tt.name, tt.flip, y, s.y)
+		}
+		if y-fontHeight < 0 || y+lineSpacing > height || x+textWidth > width {
+			t.Errorf("%s: expected the labels inside the %dx%d playfield, got them at (%d, %d)", tt.name, width, height, x, y)
+		}
+	}
+}
+
+// memoryStorage is a Storage keeping the files in memory
+type memoryStorage map[string][]byte
+
+func (m memoryStorage) ReadFile(name string) ([]byte, error) {
+	b, ok := m[name]
+	if !ok {
+		return nil, errUnsupported
+	}
+	return b, nil
+}
+
+func (m memoryStorage) WriteFile(name string, data []byte) error {
+	m[name] = data
+	return nil
+}
+
+func (m memoryStorage) WriteUnique(base, ext string, data []byte) (string, error) {
+	return base + ext, m.WriteFile(base+ext, data)
+}
+
+func (m memoryStorage) TempDir(prefix string) (string, error) {
+	return "", errUnsupported
+}
+
+func TestAutosaveRecovery(t *testing.T) {
+	storage := memoryStorage{}
+	g := &Game{storage: storage, autosave: time.Second}
+	g.addSprite("Q0", 10, 20, 1)
+	for i := 1; i < ebiten.MaxTPS(); i++ {
+		g.updateAutosave()
+	}
+	if _, ok := storage[recoveryFileName]; ok {
+		t.Fatalf("expected no recovery file before a second of ticks")
+	}
+	g.updateAutosave()
+	if _, ok := storage[recoveryFileName]; !ok {
+		t.Fatalf("expected a recovery file after a second of ticks")
+	}
+
+	// the next session is offered the file, and doesn't overwrite it until answered
+	next := &Game{storage: storage, autosave: time.Second}
+	next.offerRecovery()
+	if !next.recoveryOffered {
+		t.Fatalf("expected the recovery file to be offered")
+	}
+	for i := 0; i < 2*ebiten.MaxTPS(); i++ {
+		next.updateAutosave()
+	}
+	next.answerRecovery(true)
+	if len(next.sprites) != 1 || next.sprites[0].name != "Q0" || next.recoveryOffered {
+		t.Errorf("expected Q0 recovered and the offer answered, got %d sprites", len(next.sprites))
+	}
+}
+
+func TestSVGLabelsStayInPlayfield(t *testing.T) {
+	g := &Game{width: fullScreenWidth, height: fullScreenHeight, k: charges.K, exponent: 2, Font: theGame.Font, fontSize: theGame.fontSize}
+	width, height := g.playfield()
+	w, h := neutralImage.Size()
+	q0 := g.addSprite("Q0", 100, 100, 1)
+	g.addSprite("Q1", width-w, height-h, -1)
+	g.selectOnly(q0)
+
+	b := &bytes.Buffer{}
+	if err := g.ExportSVG(b); err != nil {
+		t.Fatal(err)
+	}
+	labels := regexp.MustCompile(`<text x="(-?\d+)" y="(-?\d+)" fill="[^"]*">((?:F|E)= [^<]*)</text>`).FindAllStringSubmatch(b.String(), -1)
+	if len(labels) != 2 {
+		t.Fatalf("expected the force and field labels of Q1, got %d labels", len(labels))
+	}
+	for _, l := range labels {
+		x, _ := strconv.Atoi(l[1])
+		y, _ := strconv.Atoi(l[2])
+		if x < 0 || y-fontHeight < 0 || y > height || x+font.MeasureString(g.Font, l[3]).Ceil() > width {
+			t.Errorf("expected %q inside the %dx%d playfield, got it at (%d, %d)", l[3], width, height, x, y)
+		}
+	}
+}
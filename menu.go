@@ -0,0 +1,99 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+// menuItem is an entry of the context menu, acting on the sprite the menu was opened on
+type menuItem struct {
+	label  string
+	action func(g *Game, s *Sprite)
+}
+
+// contextMenu is the menu opened by right-clicking a sprite, with its top left corner at (x, y)
+type contextMenu struct {
+	sprite *Sprite
+	x, y   int
+	items  []*menuItem
+}
+
+// contextMenuItems are the entries of the context menu, doing what the keyboard shortcuts do
+var contextMenuItems = []*menuItem{
+	{"Delete", func(g *Game, s *Sprite) {
+		before := totalCharge(g)
+		g.deleteSprite(s)
+		g.showChargeLedger(before)
+	}},
+	{"Duplicate", (*Game).duplicate},
+	{"Pin/Unpin", func(g *Game, s *Sprite) {
+		s.fixed = !s.fixed
+		s.vx, s.vy = 0, 0
+	}},
+	{"Set Charge", func(g *Game, s *Sprite) {
+		g.selectOnly(s)
+		g.startInput(inputCharge)
+	}},
+}
+
+// openContextMenu opens the context menu of s at (x, y), moved so it stays on the screen, and selects s
+func (g *Game) openContextMenu(s *Sprite, x, y int) {
+	m := &contextMenu{sprite: s, items: contextMenuItems}
+	w, h := m.size()
+	if x+w > g.width {
+		x = g.width - w
+	}
+	if y+h > g.height {
+		y = g.height - h
+	}
+	m.x, m.y = x, y
+	g.menu = m
+	g.selectOnly(s)
+}
+
+// size returns the size of the menu on screen, fitting its longest label
+func (m *contextMenu) size() (int, int) {
+	width := 0
+	for _, item := range m.items {
+		if w := font.MeasureString(theGame.Font, item.label).Ceil(); w > width {
+			width = w
+		}
+	}
+	return width + fontHeight, len(m.items) * menuItemHeight()
+}
+
+// menuItemHeight is the height of each entry of the context menu
+func menuItemHeight() int {
+	return fontHeight * 2
+}
+
+// itemAt returns the entry of the menu at (x, y), or nil if (x, y) is out of the menu
+func (m *contextMenu) itemAt(x, y int) *menuItem {
+	w, h := m.size()
+	if x < m.x || x >= m.x+w || y < m.y || y >= m.y+h {
+		return nil
+	}
+	return m.items[(y-m.y)/menuItemHeight()]
+}
+
+// drawContextMenu draws the open context menu, highlighting the entry under the cursor
+func drawContextMenu(screen *ebiten.Image, g *Game) {
+	m := g.menu
+	if m == nil {
+		return
+	}
+	w, _ := m.size()
+	hovered := m.itemAt(ebiten.CursorPosition())
+	for i, item := range m.items {
+		y := m.y + i*menuItemHeight()
+		clr := color.NRGBA{0x40, 0x40, 0x40, 0xf0}
+		if item == hovered {
+			clr = color.NRGBA{0x70, 0x70, 0x70, 0xf0}
+		}
+		drawRectangle(screen, float64(m.x), float64(y), float64(w), float64(menuItemHeight()), clr)
+		text.Draw(screen, item.label, g.Font, m.x+fontHeight/2, y+menuItemHeight()/2+fontHeight/2, color.White)
+	}
+}
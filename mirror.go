@@ -0,0 +1,58 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+var mirrorColor = color.NRGBA{0x40, 0xe0, 0xe0, 0xff}
+
+// toggleMirror links the two selected sprites as a mirrored pair, or unlinks them if they already are one.
+// Dragging a sprite of a pair moves the other one by the same delta mirrored across the vertical center line
+// of the playfield, so symmetric configurations stay symmetric. A sprite is in one pair at most,
+// linking it again breaks its previous pair.
+func (g *Game) toggleMirror() {
+	selected := g.selectedSprites()
+	if len(selected) != 2 {
+		return
+	}
+	g.pushUndo()
+	s1, s2 := selected[0], selected[1]
+	if s1.mirror == s2 {
+		g.unlinkMirror(s1)
+		return
+	}
+	g.unlinkMirror(s1)
+	g.unlinkMirror(s2)
+	s1.mirror, s2.mirror = s2, s1
+}
+
+// unlinkMirror breaks the mirrored pair of s, if it is in one
+func (g *Game) unlinkMirror(s *Sprite) {
+	if s.mirror != nil {
+		s.mirror.mirror = nil
+		s.mirror = nil
+	}
+}
+
+// drawMirrors draws the vertical center line the pairs are mirrored across, and links each pair,
+// when there is any
+func drawMirrors(screen *ebiten.Image, g *Game) {
+	clr := overlayColor(fade(mirrorColor, .4))
+	drawn := map[*Sprite]bool{}
+	for _, s := range g.sprites {
+		// each pair is drawn once, from its first sprite
+		if s.mirror == nil || drawn[s.mirror] {
+			continue
+		}
+		x1, y1 := s.center()
+		x2, y2 := s.mirror.center()
+		drawLine(screen, x1, y1, x2, y2, clr)
+		drawn[s] = true
+	}
+	if len(drawn) > 0 {
+		width, height := g.playfield()
+		drawLine(screen, float64(width)/2, 0, float64(width)/2, float64(height), clr)
+	}
+}
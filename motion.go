@@ -0,0 +1,145 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// step advances the motion of the charges by one tick, scaled by the time scale.
+// Ticks longer than maxStepDt are split in several steps, so speeding up the motion doesn't make it unstable.
+// The tick is fixed whatever the time since the last one, so the motion resumes without a jump after the game
+// was in the background, where Ebiten stops calling Update.
+func (g *Game) step() {
+	dt := g.timeScale / float64(ebiten.MaxTPS())
+	n := int(math.Ceil(dt / maxStepDt))
+	for i := 0; i < n; i++ {
+		g.integrate(dt / float64(n))
+	}
+}
+
+// integrate moves the charges under their net Coulomb forces during dt seconds,
+// using a semi-implicit Euler step: velocities are updated first and then used to move the charges.
+// The charges being dragged stay put, still exerting their forces, so the mouse and the motion don't fight.
+func (g *Game) integrate(dt float64) {
+	g.updateGrid()
+
+	// all forces are computed before anything moves, so the order of the sprites doesn't matter
+	forces := make([][2]float64, len(g.sprites))
+	for i, s := range g.sprites {
+		forces[i][0], forces[i][1] = netForce(g, s)
+	}
+
+	dragged := g.draggedSprites()
+	absorbed := []*Sprite{}
+	for i, s := range g.sprites {
+		if _, ok := dragged[s]; s.fixed || ok {
+			continue
+		}
+		s.vx += forces[i][0] / s.mass * dt
+		s.vy += forces[i][1] / s.mass * dt
+		if speed := math.Hypot(s.vx, s.vy); speed > maxSpeed {
+			s.vx *= maxSpeed / speed
+			s.vy *= maxSpeed / speed
+		}
+		if s.moveByVelocity(dt, g.boundary) {
+			absorbed = append(absorbed, s)
+			continue
+		}
+		s.recordTrail(g.trailLength)
+	}
+	// deleted once everything moved, so g.sprites and forces stay aligned above
+	for _, s := range absorbed {
+		g.deleteSprite(s)
+	}
+}
+
+// moveByVelocity moves the sprite by its velocity during dt seconds.
+// The fraction of pixel left is kept for the next step, so slow charges still move.
+// Charges reaching the edges of the playfield follow mode, and true is returned if they must be absorbed.
+func (s *Sprite) moveByVelocity(dt float64, mode BoundaryMode) bool {
+	// velocities are in m/s, and 1 m is unitScale px on screen
+	s.remainderX += s.vx * dt * theGame.unitScale
+	s.remainderY += s.vy * dt * theGame.unitScale
+	dx, dy := int(s.remainderX), int(s.remainderY)
+	s.remainderX -= float64(dx)
+	s.remainderY -= float64(dy)
+
+	if mode == BoundaryWrap {
+		w, h := s.image.Size()
+		width, height := theGame.playfield()
+		x, y := wrap(s.x+dx, width-w), wrap(s.y+dy, height-h)
+		if x != s.x+dx || y != s.y+dy {
+			// the trail would otherwise cross the whole playfield
+			s.clearTrail()
+		}
+		s.x, s.y = x, y
+		return false
+	}
+
+	x, y := s.x, s.y
+	s.MoveBy(dx, dy)
+	hitX, hitY := s.x != x+dx, s.y != y+dy
+	if mode == BoundaryAbsorb {
+		return hitX || hitY
+	}
+	// clamped charges lose their speed towards the edge, bouncing ones move away from it
+	bounce := 0.
+	if mode == BoundaryBounce {
+		bounce = -1
+	}
+	if hitX {
+		s.vx *= bounce
+		s.remainderX = 0
+	}
+	if hitY {
+		s.vy *= bounce
+		s.remainderY = 0
+	}
+	return false
+}
+
+// recordTrail adds the current center of the sprite to its trail, dropping the oldest position once length are kept.
+// The buffer is only allocated the first time, or when the length changes.
+func (s *Sprite) recordTrail(length int) {
+	if length <= 0 {
+		return
+	}
+	if len(s.trail) != length {
+		s.trail = make([][2]float64, length)
+		s.trailStart, s.trailLen = 0, 0
+	}
+	x, y := s.center()
+	if s.trailLen < length {
+		s.trail[(s.trailStart+s.trailLen)%length] = [2]float64{x, y}
+		s.trailLen++
+		return
+	}
+	s.trail[s.trailStart] = [2]float64{x, y}
+	s.trailStart = (s.trailStart + 1) % length
+}
+
+// clearTrail forgets the positions in the trail of the sprite, keeping its buffer
+func (s *Sprite) clearTrail() {
+	s.trailStart, s.trailLen = 0, 0
+}
+
+// drawTrail draws the trail of the sprite, fading from the current position to the oldest one
+func (s *Sprite) drawTrail(screen *ebiten.Image) {
+	clr := chargeColor(s.charge)
+	for i := 1; i < s.trailLen; i++ {
+		p1 := s.trail[(s.trailStart+i-1)%len(s.trail)]
+		p2 := s.trail[(s.trailStart+i)%len(s.trail)]
+		drawLine(screen, p1[0], p1[1], p2[0], p2[1], overlayColor(fade(clr, float64(i)/float64(s.trailLen))))
+	}
+}
+
+// drawVelocity draws the velocity of a moving sprite as an arrow from its center
+func (s *Sprite) drawVelocity(screen *ebiten.Image) {
+	speed := math.Hypot(s.vx, s.vy)
+	if speed == 0 {
+		return
+	}
+	x, y := s.center()
+	drawArrow(screen, x, y, math.Atan2(s.vy, s.vx), math.Min(speed*velocityArrowScale, arrowMaxLength), overlayColor(velocityColor))
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+// noticeTicks is how many ticks a notice stays on screen
+const noticeTicks = 120
+
+// showNotice shows str in the middle of the playfield for noticeTicks, replacing any notice still shown
+func (g *Game) showNotice(str string) {
+	g.notice = str
+	g.noticeTicks = noticeTicks
+}
+
+// updateNotice counts down the ticks left for the notice shown
+func (g *Game) updateNotice() {
+	if g.noticeTicks > 0 {
+		g.noticeTicks--
+	}
+}
+
+// drawNotice draws the notice shown, if any, on a dark box in the middle of the playfield
+func drawNotice(screen *ebiten.Image, g *Game) {
+	if g.noticeTicks == 0 {
+		return
+	}
+	width, height := g.playfield()
+	margin := fontHeight / 2
+	w := font.MeasureString(g.Font, g.notice).Ceil() + 2*margin
+	h := fontHeight + 2*margin
+	x, y := (width-w)/2, (height-h)/2
+	drawRectangle(screen, float64(x), float64(y), float64(w), float64(h), color.NRGBA{0x00, 0x00, 0x00, 0xc0})
+	text.Draw(screen, g.notice, g.Font, x+margin, y+margin+fontHeight, offScaleColor)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+// nullPointSize is the half size in px of the cross marking the null point
+const nullPointSize = 6
+
+// nullPoint finds where the field of the two charges of the game cancels out, on the screen.
+// The fields of q1 and q2 have the same magnitude where r1/r2 = |q1/q2|^(1/exponent), which is
+// r1/r2 = sqrt(|q1/q2|) for Coulomb's law, and they point in opposite directions only between
+// charges of the same sign. ok is false unless there are exactly two charges (not counting the neutral ones)
+// of the same sign, as charges of opposite signs have no null point between them.
+func nullPoint(g *Game) (x, y float64, ok bool) {
+	charged := []*Sprite{}
+	for _, s := range g.sprites {
+		if s.charge != 0 {
+			charged = append(charged, s)
+		}
+	}
+	if len(charged) != 2 || charged[0].charge*charged[1].charge < 0 {
+		return 0, 0, false
+	}
+	s1, s2 := charged[0], charged[1]
+	ratio := math.Pow(math.Abs(s1.charge/s2.charge), 1/g.exponent)
+	// r1 = ratio * r2 and r1 + r2 is the distance between the charges
+	t := ratio / (1 + ratio)
+	x1, y1 := s1.center()
+	x2, y2 := s2.center()
+	return x1 + t*(x2-x1), y1 + t*(y2-y1), true
+}
+
+// drawNullPoint marks the null point of the two charges with a cross, if they have one
+func drawNullPoint(screen *ebiten.Image, g *Game) {
+	x, y, ok := nullPoint(g)
+	if !ok {
+		return
+	}
+	clr := overlayColor(nullPointColor)
+	drawLine(screen, x-nullPointSize, y-nullPointSize, x+nullPointSize, y+nullPointSize, clr)
+	drawLine(screen, x-nullPointSize, y+nullPointSize, x+nullPointSize, y-nullPointSize, clr)
+}
@@ -0,0 +1,54 @@
+package main
+
+import "math"
+
+// maxPlacementTries is how many random positions are tried for a new charge before searching for a free spot
+const maxPlacementTries = 50
+
+// overlapsAny reports if a w by h box with its top left corner at (x, y) overlaps the box of any sprite,
+// as drawn on screen
+func (g *Game) overlapsAny(x, y, w, h int) bool {
+	for _, s := range g.sprites {
+		cx, cy := s.center()
+		sw, sh := s.image.Size()
+		halfW, halfH := float64(sw)/2*s.scale(), float64(sh)/2*s.scale()
+		if float64(x) < cx+halfW && cx-halfW < float64(x+w) && float64(y) < cy+halfH && cy-halfH < float64(y+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// freePosition returns the top left corner of a w by h box fully inside the playfield not overlapping any sprite.
+// Random positions are tried first, then a spiral going out from the center of the playfield
+// finds a spot when it is crowded. If there is none, the last random position is returned.
+func (g *Game) freePosition(w, h int) (int, int) {
+	width, height := g.playfield()
+	var x, y int
+	for i := 0; i < maxPlacementTries; i++ {
+		x, y = g.rand.Intn(width-w), g.rand.Intn(height-h)
+		if !g.overlapsAny(x, y, w, h) {
+			return x, y
+		}
+	}
+
+	// square spiral with steps of half a box, turning every 1, 1, 2, 2, 3, 3... steps
+	cx, cy := (width-w)/2, (height-h)/2
+	step := int(math.Max(1, float64(w/2)))
+	sx, sy := 0, 0
+	dx, dy := 1, 0
+	limit := int(math.Max(float64(width), float64(height))/float64(step)) + 1
+	for run := 1; run <= limit; run++ {
+		for turn := 0; turn < 2; turn++ {
+			for i := 0; i < run; i++ {
+				px, py := cx+sx*step, cy+sy*step
+				if px >= 0 && py >= 0 && px+w <= width && py+h <= height && !g.overlapsAny(px, py, w, h) {
+					return px, py
+				}
+				sx, sy = sx+dx, sy+dy
+			}
+			dx, dy = -dy, dx
+		}
+	}
+	return x, y
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+	"golang.org/x/image/font"
+)
+
+const (
+	probeRadius      = 6  // px, half the size of the probe marker, and how close a click must be to remove it
+	probeArrowLength = 30 // px, the field arrows of the probes all have the same length
+)
+
+var probeColor = color.NRGBA{0xc0, 0xc0, 0xff, 0xff}
+
+// probe is a point of the playfield where the field and the potential are shown, placed in probe mode
+type probe struct {
+	x, y int
+}
+
+// probeAt returns the probe close enough to (x, y) to be clicked, or nil if none is
+func (g *Game) probeAt(x, y int) *probe {
+	for _, p := range g.probes {
+		if math.Hypot(float64(x-p.x), float64(y-p.y)) <= probeRadius {
+			return p
+		}
+	}
+	return nil
+}
+
+// toggleProbe removes the probe at (x, y), or places a new one there if there is none
+func (g *Game) toggleProbe(x, y int) *probe {
+	if p := g.probeAt(x, y); p != nil {
+		for i, pp := range g.probes {
+			if pp == p {
+				g.probes = append(g.probes[:i], g.probes[i+1:]...)
+				break
+			}
+		}
+		return p
+	}
+	p := &probe{x, y}
+	g.probes = append(g.probes, p)
+	return p
+}
+
+// drawProbes draws each probe as a square with the direction of the field there,
+// and the summed field and potential of all the charges next to it
+func drawProbes(screen *ebiten.Image, g *Game) {
+	clr := overlayColor(probeColor)
+	for _, p := range g.probes {
+		x, y := float64(p.x), float64(p.y)
+		drawLine(screen, x-probeRadius, y-probeRadius, x+probeRadius, y-probeRadius, clr)
+		drawLine(screen, x+probeRadius, y-probeRadius, x+probeRadius, y+probeRadius, clr)
+		drawLine(screen, x+probeRadius, y+probeRadius, x-probeRadius, y+probeRadius, clr)
+		drawLine(screen, x-probeRadius, y+probeRadius, x-probeRadius, y-probeRadius, clr)
+
+		ex, ey := fieldAt(g, x, y)
+		magnitude := math.Hypot(ex, ey)
+		a := math.Atan2(ey, ex)
+		if magnitude > 0 {
+			drawArrow(screen, x, y, a, probeArrowLength, clr)
+		}
+		for i, t := range probeTexts(g, p.x, p.y) {
+			text.Draw(screen, t.text, g.Font, p.x+probeRadius*2, p.y-probeRadius+i*(fontHeight+fontHeight/2), overlayColor(t.color))
+		}
+	}
+}
+
+// probeTexts returns the summed field and potential of all the charges at (x, y), as shown by the probes
+func probeTexts(g *Game, x, y int) []coloredText {
+	ex, ey := fieldAt(g, float64(x), float64(y))
+	magnitude := math.Hypot(ex, ey)
+	fieldText, fieldColor := formatValue("E = %.2e N/C", magnitude)
+	if magnitude > 0 {
+		fieldText += fmt.Sprintf(" at %.0f°", math.Atan2(ey, ex)*180/math.Pi)
+	}
+	potentialText, potentialColor := formatValue("V = %.2e V", potentialAt(g, x, y))
+	return []coloredText{{fieldText, fieldColor}, {potentialText, potentialColor}}
+}
+
+// drawHoverProbe shows the field and the potential under the mouse cursor in a tooltip following it,
+// like a transient probe. It is only shown over an empty spot of the playfield, with nothing selected or dragged.
+func drawHoverProbe(screen *ebiten.Image, g *Game) {
+	if len(g.strokes) > 0 || len(g.selected) > 0 || g.menu != nil || g.inputMode != inputNone {
+		return
+	}
+	x, y := ebiten.CursorPosition()
+	width, height := g.playfield()
+	if x < 0 || y < 0 || x >= width || y >= height || g.spriteAt(x, y) != nil || g.probeAt(x, y) != nil || g.buttonAt(x, y) != nil {
+		return
+	}
+	if _, inside := g.inspectorRowAt(x, y); inside {
+		return
+	}
+
+	texts := probeTexts(g, x, y)
+	lineHeight := fontHeight + fontHeight/2
+	margin := fontHeight / 2
+	boxWidth := 0
+	for _, t := range texts {
+		if w := font.MeasureString(g.Font, t.text).Ceil(); w > boxWidth {
+			boxWidth = w
+		}
+	}
+	boxWidth += 2 * margin
+	boxHeight := len(texts)*lineHeight + margin
+	// below and to the right of the cursor, unless that leaves the playfield
+	left, top := x+probeRadius*2, y+probeRadius*2
+	if left+boxWidth > width {
+		left = x - probeRadius - boxWidth
+	}
+	if top+boxHeight > height {
+		top = y - probeRadius - boxHeight
+	}
+	drawRectangle(screen, float64(left), float64(top), float64(boxWidth), float64(boxHeight), color.NRGBA{0x00, 0x00, 0x00, 0xc0})
+	for i, t := range texts {
+		text.Draw(screen, t.text, g.Font, left+margin, top+(i+1)*lineHeight, t.color)
+	}
+}
+
+// snapContour sets the level of the equipotential drawn in probe mode to the potential at (x, y),
+// so it goes through that point and all the others at the same potential
+func (g *Game) snapContour(x, y int) {
+	v := potentialAt(g, x, y)
+	g.contourLevel = &v
+}
+
+// contourReadout describes the equipotential drawn in probe mode, or how to draw one
+func contourReadout(g *Game) string {
+	if g.contourLevel == nil {
+		return "'Shift+V' draws the equipotential through the cursor"
+	}
+	return fmt.Sprintf("Equipotential at V = %.2e V ('Shift+V' through the cursor)", *g.contourLevel)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+)
+
+const (
+	recordFrameInterval = 2  // one of every recordFrameInterval frames is saved while recording
+	recordQueueSize     = 30 // frames waiting to be written before new ones are dropped
+)
+
+// recordedFrame is a frame waiting to be written to disk
+type recordedFrame struct {
+	path  string
+	image *image.RGBA
+}
+
+// Recorder saves the frames drawn on the screen as a numbered sequence of PNG files,
+// so they can be assembled into a video externally.
+type Recorder struct {
+	storage Storage
+	dir     string
+	frame   int
+	saved   int
+	dropped int
+	queue   chan recordedFrame
+	done    chan struct{}
+}
+
+// NewRecorder creates a new directory for the frames in storage and starts the routine writing them
+func NewRecorder(storage Storage) (*Recorder, error) {
+	dir, err := storage.TempDir("recording-")
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		storage: storage,
+		dir:     dir,
+		queue:   make(chan recordedFrame, recordQueueSize),
+		done:    make(chan struct{}),
+	}
+	go r.write()
+	log.Printf("recording frames to %s", dir)
+	return r, nil
+}
+
+// write encodes the queued frames to disk until the recorder is stopped
+func (r *Recorder) write() {
+	defer close(r.done)
+	for f := range r.queue {
+		if err := savePNG(r.storage, f.path, f.image); err != nil {
+			log.Printf("could not save frame: %v", err)
+		}
+	}
+}
+
+// Capture queues the screen to be saved if the current frame is one to be recorded.
+// Frames are dropped with a warning if the disk writes can't keep up.
+func (r *Recorder) Capture(screen *ebiten.Image) {
+	r.frame++
+	if r.frame%recordFrameInterval != 0 {
+		return
+	}
+	if len(r.queue) == cap(r.queue) {
+		if r.dropped == 0 {
+			log.Printf("disk writes can't keep up with the recording, dropping frames")
+		}
+		r.dropped++
+		return
+	}
+	r.saved++
+	r.queue <- recordedFrame{
+		path:  filepath.Join(r.dir, fmt.Sprintf("frame-%05d.png", r.saved)),
+		image: toRGBA(screen),
+	}
+}
+
+// Stop waits for the queued frames to be written
+func (r *Recorder) Stop() {
+	close(r.queue)
+	<-r.done
+	log.Printf("recorded %d frames to %s (%d dropped)", r.saved, r.dir, r.dropped)
+}
+
+// SaveScreenshot draws the current frame offscreen and saves it to a PNG file named after the current time,
+// returning the name of the file
+func (g *Game) SaveScreenshot() (string, error) {
+	offscreen, err := ebiten.NewImage(g.width, g.height, ebiten.FilterDefault)
+	if err != nil {
+		return "", err
+	}
+	defer offscreen.Dispose()
+	offscreen.Fill(color.Black)
+	g.draw(offscreen)
+
+	b := &bytes.Buffer{}
+	if err := png.Encode(b, toRGBA(offscreen)); err != nil {
+		return "", err
+	}
+	return g.storage.WriteUnique("screenshot-"+time.Now().Format("20060102-150405"), ".png", b.Bytes())
+}
+
+// toRGBA reads the pixels of an ebiten image back into an image.RGBA
+func toRGBA(img *ebiten.Image) *image.RGBA {
+	w, h := img.Size()
+	rgba := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			rgba.Set(x, y, img.At(x, y))
+		}
+	}
+	return rgba
+}
+
+// savePNG encodes an image to a PNG file at path in storage
+func savePNG(storage Storage, path string, img image.Image) error {
+	b := &bytes.Buffer{}
+	if err := png.Encode(b, img); err != nil {
+		return err
+	}
+	return storage.WriteFile(path, b.Bytes())
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log"
+	"math"
+)
+
+const (
+	relaxMaxMove   = 5    // px moved by the charge under the strongest force on the first relaxation step
+	relaxDamping   = 0.99 // the moves shrink by this factor at each step, so the charges settle instead of oscillating
+	relaxTolerance = 1e-3 // the relaxation stops once the strongest net force falls below this fraction of the initial one
+	relaxMaxSteps  = 600
+)
+
+// startRelaxing starts moving the free charges towards an equilibrium, one step every tick, pausing the motion
+func (g *Game) startRelaxing() {
+	g.playing = false
+	g.relaxing = true
+	g.relaxSteps = 0
+	g.relaxInitialForce = 0
+}
+
+// relax moves every free charge along its net force, the one under the strongest force by relaxMaxMove px damped
+// by the steps already done and the others proportionally. It stops when the strongest force becomes small enough,
+// after relaxMaxSteps or when the moves become too small to change the positions.
+// As Earnshaw's theorem shows, free charges alone have no stable equilibrium: without fixed charges or the edges
+// holding them, the relaxation mostly pushes them apart.
+func (g *Game) relax() {
+	forces := make([][2]float64, len(g.sprites))
+	maxForce := 0.
+	dragged := g.draggedSprites()
+	for i, s := range g.sprites {
+		if _, ok := dragged[s]; s.fixed || ok {
+			continue
+		}
+		forces[i][0], forces[i][1] = netForce(g, s)
+		maxForce = math.Max(maxForce, math.Hypot(forces[i][0], forces[i][1]))
+	}
+	if g.relaxSteps == 0 {
+		g.relaxInitialForce = maxForce
+	}
+
+	move := relaxMaxMove * math.Pow(relaxDamping, float64(g.relaxSteps))
+	switch {
+	case maxForce == 0 || maxForce < relaxTolerance*g.relaxInitialForce:
+		log.Printf("equilibrium found after %d steps", g.relaxSteps)
+		g.relaxing = false
+		return
+	case g.relaxSteps >= relaxMaxSteps || move < 0.5:
+		log.Printf("no equilibrium found after %d steps, the strongest net force is %.2e N", g.relaxSteps, maxForce)
+		g.relaxing = false
+		return
+	}
+
+	for i, s := range g.sprites {
+		if _, ok := dragged[s]; s.fixed || ok {
+			continue
+		}
+		s.MoveBy(int(math.Round(move*forces[i][0]/maxForce)), int(math.Round(move*forces[i][1]/maxForce)))
+		s.vx, s.vy = 0, 0
+	}
+	g.relaxSteps++
+	g.updateGrid()
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+)
+
+const (
+	maxRulerWidth = 150 // px, the ruler is the longest round length fitting in it
+	rulerTickSize = 4   // half height in px of the ticks at the ends of the ruler
+)
+
+// rulerLength returns the longest length of 1, 2 or 5 times a power of 10 m taking at most maxWidth px
+// on screen, with unitScale px for each m
+func rulerLength(unitScale, maxWidth float64) float64 {
+	max := maxWidth / unitScale
+	length := math.Pow(10, math.Floor(math.Log10(max)))
+	for _, m := range []float64{5, 2} {
+		if length*m <= max {
+			return length * m
+		}
+	}
+	return length
+}
+
+// drawRuler draws a scale bar on the bottom left of the playfield, showing how long a round number of meters is
+func drawRuler(screen *ebiten.Image, g *Game) {
+	_, height := g.playfield()
+	length := rulerLength(g.unitScale, maxRulerWidth)
+	x1 := float64(fullScreenWidth * .01)
+	x2 := x1 + length*g.unitScale
+	y := float64(height - fontHeight/2)
+	clr := overlayColor(color.White)
+	drawLine(screen, x1, y, x2, y, clr)
+	drawLine(screen, x1, y-rulerTickSize, x1, y+rulerTickSize, clr)
+	drawLine(screen, x2, y-rulerTickSize, x2, y+rulerTickSize, clr)
+	text.Draw(screen, fmt.Sprintf("%g m", length), theGame.Font, int(x1)+rulerTickSize, int(y)-rulerTickSize, clr)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/auyer/electrical-charges/charges/scene"
+)
+
+const sceneFileName = "scene.json"
+
+// SceneFile and SceneCharge are the JSON representation of a scene saved to disk,
+// defined in charges/scene so tools can read the scenes without opening a window
+type (
+	SceneFile   = scene.File
+	SceneCharge = scene.Charge
+)
+
+// sceneCharge returns the sprite as a SceneCharge
+func (s *Sprite) sceneCharge() SceneCharge {
+	return SceneCharge{
+		Name:   s.name,
+		X:      s.x,
+		Y:      s.y,
+		Z:      s.z,
+		Charge: s.charge,
+		Mass:   s.mass,
+		Fixed:  s.fixed,
+	}
+}
+
+// scene returns the charges of the game as a SceneFile
+func (g *Game) scene() SceneFile {
+	scene := SceneFile{Charges: []SceneCharge{}, ChargeStep: g.chargeStep}
+	index := map[*Sprite]int{}
+	for i, s := range g.sprites {
+		index[s] = i
+	}
+	for _, s := range g.sprites {
+		c := s.sceneCharge()
+		if s.mirror != nil {
+			i := index[s.mirror]
+			c.Mirror = &i
+		}
+		scene.Charges = append(scene.Charges, c)
+	}
+	return scene
+}
+
+// restore replaces the charges of the game by the ones of a scene, clearing the selection and any stroke
+func (g *Game) restore(scene SceneFile) {
+	g.sprites = []*Sprite{}
+	for _, c := range scene.Charges {
+		s := g.addSprite(c.Name, c.X, c.Y, c.Charge)
+		s.z = c.Z
+		if c.Mass > 0 {
+			s.mass = c.Mass
+		}
+		s.fixed = c.Fixed
+		s.image = imageFor(s.charge)
+	}
+	// linked once all the sprites exist, ignoring the links not going both ways
+	for i, c := range scene.Charges {
+		if c.Mirror == nil || *c.Mirror < 0 || *c.Mirror >= len(g.sprites) || *c.Mirror == i {
+			continue
+		}
+		if m := scene.Charges[*c.Mirror].Mirror; m != nil && *m == i {
+			g.sprites[i].mirror = g.sprites[*c.Mirror]
+		}
+	}
+	if scene.ChargeStep > 0 {
+		g.chargeStep = scene.ChargeStep
+	}
+	g.selectOnly(nil)
+	g.strokes = nil
+	g.menu = nil
+	g.energy.clear()
+	g.isolated = nil
+}
+
+// Save writes all the charges of the game to a JSON file at path in the storage of the game
+func (g *Game) Save(path string) error {
+	scene := g.scene()
+	scene.NetForcesOnly = g.netForcesOnly
+	b, err := json.MarshalIndent(scene, "", "  ")
+	if err != nil {
+		return err
+	}
+	return g.storage.WriteFile(path, b)
+}
+
+// Load replaces the charges of the game by the ones in the JSON file at path in the storage of the game.
+// The game is left untouched if the file can't be read.
+func (g *Game) Load(path string) error {
+	b, err := g.storage.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := scene.Parse(b)
+	if err != nil {
+		return err
+	}
+	g.restore(f)
+	g.netForcesOnly = f.NetForcesOnly
+	return nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/text"
+)
+
+const (
+	sliderRange        = 5   // C, the slider goes from -sliderRange to sliderRange, or to maxCharge if lower
+	sliderWidth        = 300 // px
+	sliderHandleRadius = 6   // px, also how far from the track a press still grabs the slider
+)
+
+// chargeSlider is the object dragged by a press on the slider, setting the charge of s
+type chargeSlider struct {
+	s *Sprite
+}
+
+// sliderLayout returns the left end, the vertical center and the range in C of the slider,
+// centered on the bottom edge of the playfield, below the texts drawn there
+func (g *Game) sliderLayout() (x, y int, limit float64) {
+	width, height := g.playfield()
+	return (width - sliderWidth) / 2, height - fontHeight, math.Min(sliderRange, g.maxCharge)
+}
+
+// inSlider reports whether (x, y) presses the slider, shown while a sprite is chosen
+func (g *Game) inSlider(x, y int) bool {
+	if g.ChosenSprite == nil {
+		return false
+	}
+	left, center, _ := g.sliderLayout()
+	return x >= left-sliderHandleRadius && x <= left+sliderWidth+sliderHandleRadius &&
+		y >= center-2*sliderHandleRadius && y <= center+2*sliderHandleRadius
+}
+
+// slide sets the charge of the sprite of the slider from the position x of the handle, rounded to minChargeStep
+func (g *Game) slide(c *chargeSlider, x int) {
+	// the sprite may have been deleted or unselected while sliding
+	if c.s != g.ChosenSprite {
+		return
+	}
+	left, _, limit := g.sliderLayout()
+	t := math.Max(0, math.Min(float64(x-left)/sliderWidth, 1))
+	q := math.Round((2*t-1)*limit/minChargeStep) * minChargeStep
+	if q == 0 {
+		// no -0 when sliding back to neutral from the negative side
+		q = 0
+	}
+	c.s.charge = q
+	c.s.image = imageFor(q)
+}
+
+// drawSlider draws the slider of the charge of the chosen sprite, its handle at the current charge
+func drawSlider(screen *ebiten.Image, g *Game) {
+	s := g.ChosenSprite
+	if s == nil {
+		return
+	}
+	left, center, limit := g.sliderLayout()
+	x, y := float64(left), float64(center)
+	clr := overlayColor(color.White)
+	drawLine(screen, x, y, x+sliderWidth, y, clr)
+	// ticks at both ends and at zero
+	for _, tx := range []float64{x, x + sliderWidth/2, x + sliderWidth} {
+		drawLine(screen, tx, y-sliderHandleRadius, tx, y+sliderHandleRadius, clr)
+	}
+	text.Draw(screen, fmt.Sprintf("%s: %+.3f C (±%g)", s.name, s.charge, limit), g.Font, left+sliderWidth+fontHeight, center+fontHeight/2, clr)
+
+	t := math.Max(0, math.Min((s.charge/limit+1)/2, 1))
+	hx := x + t*sliderWidth
+	drawRectangle(screen, hx-sliderHandleRadius, y-sliderHandleRadius, 2*sliderHandleRadius, 2*sliderHandleRadius, chargeColor(s.charge))
+}
@@ -0,0 +1,20 @@
+package main
+
+import "errors"
+
+// errUnsupported is returned by the storages that can't do what is asked, like recording in the browser
+var errUnsupported = errors.New("not supported on this platform")
+
+// Storage persists the files read and written by the game: scenes, screenshots, exports and recordings.
+// The implementation depends on the platform, files on the desktop and localStorage in the browser.
+type Storage interface {
+	// ReadFile returns the content of the file named name
+	ReadFile(name string) ([]byte, error)
+	// WriteFile writes data to the file named name, replacing it if it exists
+	WriteFile(name string, data []byte) error
+	// WriteUnique writes data to a new file named base+ext, adding a number to base if such a file exists,
+	// and returns the name used
+	WriteUnique(base, ext string, data []byte) (string, error)
+	// TempDir creates a new directory whose name starts with prefix, returning its name
+	TempDir(prefix string) (string, error)
+}
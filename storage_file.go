@@ -0,0 +1,49 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// fileStorage is a Storage keeping the files in the working directory
+type fileStorage struct{}
+
+// newStorage returns the Storage of the platform
+func newStorage() Storage {
+	return fileStorage{}
+}
+
+func (fileStorage) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func (fileStorage) WriteFile(name string, data []byte) error {
+	return ioutil.WriteFile(name, data, 0644)
+}
+
+func (fileStorage) WriteUnique(base, ext string, data []byte) (string, error) {
+	path := base + ext
+	for i := 1; ; i++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			path = fmt.Sprintf("%s-%d%s", base, i, ext)
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()
+			return "", err
+		}
+		return path, f.Close()
+	}
+}
+
+func (fileStorage) TempDir(prefix string) (string, error) {
+	return ioutil.TempDir(".", prefix)
+}
@@ -0,0 +1,67 @@
+//go:build js
+// +build js
+
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/gopherjs/gopherwasm/js"
+)
+
+// localStorageKeyPrefix is added to the names of the files stored in localStorage,
+// so they don't mix with the keys of other pages of the same origin
+const localStorageKeyPrefix = "electrical-charges/"
+
+// localStorage is a Storage keeping the files base64 encoded in the localStorage of the browser
+type localStorage struct{}
+
+// newStorage returns the Storage of the platform
+func newStorage() Storage {
+	return localStorage{}
+}
+
+func (localStorage) ReadFile(name string) (data []byte, err error) {
+	defer recoverJSError(&err)
+	item := js.Global().Get("localStorage").Call("getItem", localStorageKeyPrefix+name)
+	if item.Type() == js.TypeNull {
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return base64.StdEncoding.DecodeString(item.String())
+}
+
+func (localStorage) WriteFile(name string, data []byte) (err error) {
+	// setItem throws when the storage is full
+	defer recoverJSError(&err)
+	js.Global().Get("localStorage").Call("setItem", localStorageKeyPrefix+name, base64.StdEncoding.EncodeToString(data))
+	return nil
+}
+
+func (s localStorage) WriteUnique(base, ext string, data []byte) (name string, err error) {
+	defer recoverJSError(&err)
+	name = base + ext
+	for i := 1; js.Global().Get("localStorage").Call("getItem", localStorageKeyPrefix+name).Type() != js.TypeNull; i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	return name, s.WriteFile(name, data)
+}
+
+// TempDir fails, as the recorded frames wouldn't fit in localStorage
+func (localStorage) TempDir(prefix string) (string, error) {
+	return "", errUnsupported
+}
+
+// recoverJSError turns the panic of a JavaScript exception into an error stored in err
+func recoverJSError(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	jsErr, ok := r.(js.Error)
+	if !ok {
+		panic(r)
+	}
+	*err = jsErr
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"image/color"
+	"io"
+	"math"
+	"time"
+)
+
+// svgColor formats a color as an SVG hex color, ignoring its alpha
+func svgColor(clr color.Color) string {
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// infoPairs returns the pairs of charges whose interaction is detailed on screen: the chosen charge with
+// every other one, or every pair within the selection when there is a group selected.
+// There are none in net forces only mode.
+func (g *Game) infoPairs() [][2]*Sprite {
+	pairs := [][2]*Sprite{}
+	if g.netForcesOnly {
+		return pairs
+	}
+	if len(g.selected) >= 2 {
+		selected := g.selectedSprites()
+		for i, s1 := range selected {
+			for _, s2 := range selected[i+1:] {
+				pairs = append(pairs, [2]*Sprite{s1, s2})
+			}
+		}
+		return pairs
+	}
+	if g.ChosenSprite == nil {
+		return pairs
+	}
+	for _, s := range g.drawOrder() {
+		if s != g.ChosenSprite {
+			pairs = append(pairs, [2]*Sprite{g.ChosenSprite, s})
+		}
+	}
+	return pairs
+}
+
+// ExportSVG writes the playfield as an SVG image laid out like the screen: the charges as colored circles
+// with their names, and over them for the pairs detailed on screen the linking lines, the force arrows,
+// the distances and the force and field values, labelled by pairLabels as on screen.
+// The net force arrows are written when they are drawn on screen, including the one of the chosen charge.
+func (g *Game) ExportSVG(w io.Writer) error {
+	width, height := g.playfield()
+	fontSize := g.fontSize * fontDPI / 72
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="%.1f">`+"\n", width, height, width, height, fontSize)
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="black"/>`+"\n", width, height)
+	svgText := func(x, y int, str string, clr color.Color) {
+		fmt.Fprintf(b, `<text x="%d" y="%d" fill="%s">%s</text>`+"\n", x, y, svgColor(clr), html.EscapeString(str))
+	}
+
+	for _, s := range g.drawOrder() {
+		x, y := s.center()
+		r := s.radius()
+		fmt.Fprintf(b, `<circle cx="%g" cy="%g" r="%g" fill="%s"/>`+"\n", x, y, r, svgColor(chargeColor(s.charge)))
+		if s.fixed {
+			// a square around the charge marks it as pinned, as on screen
+			fmt.Fprintf(b, `<rect x="%g" y="%g" width="%g" height="%g" fill="none" stroke="%s"/>`+"\n", x-r, y-r, 2*r, 2*r, svgColor(fixedColor))
+		}
+		if g.showNames {
+			svgText(s.x, s.y, s.name, color.White)
+		}
+	}
+
+	for _, p := range g.infoPairs() {
+		s1, s2 := p[0], p[1]
+		f := force(s1, s2)
+		clr := interactionColor(s1.charge, s2.charge)
+		x1, y1 := s1.center()
+		x2, y2 := s2.center()
+		fmt.Fprintf(b, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="%s" stroke-width="%g"/>`+"\n", x1, y1, x2, y2, svgColor(clr), linkWidth(math.Abs(f)))
+		if f != 0 && !coincident(s1, s2) {
+			// the force of s1 on s2, pointing away from s1 for a repulsion and towards it for an attraction
+			a := angle(s2, s1)
+			if f < 0 {
+				a += math.Pi
+			}
+			length := arrowLength(math.Abs(f))
+			fmt.Fprintf(b, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="%s"/>`+"\n", x2, y2, x2+length*math.Cos(a), y2+length*math.Sin(a), svgColor(clr))
+		}
+		midX, midY := midPoint(s1, s2)
+		svgText(midX, midY, fmt.Sprintf("%.2f m", distance(s1, s2)), color.White)
+		labels, x, y, lineSpacing := pairLabels(g, s1, s2)
+		for i, l := range labels {
+			svgText(x, y+i*lineSpacing, l.text, l.color)
+		}
+	}
+	if g.showForceArrows || g.netForcesOnly {
+		for _, ar := range forceArrows(g, nil) {
+			x, y := ar.s.center()
+			fmt.Fprintf(b, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="%s" stroke-width="%d"/>`+"\n", x, y, x+ar.length*math.Cos(ar.a), y+ar.length*math.Sin(ar.a), svgColor(ar.color), forceArrowWidth)
+		}
+	}
+	b.WriteString("</svg>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// SaveSVG exports the playfield to an SVG file named after the current time, returning the name of the file
+func (g *Game) SaveSVG() (string, error) {
+	b := &bytes.Buffer{}
+	if err := g.ExportSVG(b); err != nil {
+		return "", err
+	}
+	return g.storage.WriteUnique("scene-"+time.Now().Format("20060102-150405"), ".svg", b.Bytes())
+}
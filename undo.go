@@ -0,0 +1,32 @@
+package main
+
+// maxUndo is how many actions can be undone, the oldest ones being forgotten
+const maxUndo = 50
+
+// pushUndo records the current scene, so the action about to be done can be undone with Ctrl+Z
+func (g *Game) pushUndo() {
+	g.undo = append(g.undo, g.scene())
+	if len(g.undo) > maxUndo {
+		g.undo = g.undo[len(g.undo)-maxUndo:]
+	}
+}
+
+// popUndo goes back to the scene recorded before the last undoable action, if any
+func (g *Game) popUndo() {
+	if len(g.undo) == 0 {
+		return
+	}
+	g.restore(g.undo[len(g.undo)-1])
+	g.undo = g.undo[:len(g.undo)-1]
+}
+
+// invertCharges flips the sign of every charge as a single undoable action, neutral charges staying neutral
+func (g *Game) invertCharges() {
+	g.pushUndo()
+	for _, s := range g.sprites {
+		// skipping the neutral charges, which would otherwise become -0 and show as such
+		if s.charge != 0 {
+			s.charge = -s.charge
+		}
+	}
+}